@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/mdelgado509/go-worktree/internal/config"
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// aliasArgs are the arguments given to a user-defined alias, the data of
+// the templates in its command line. {{.}} is all of them, separated by
+// spaces, and {{index . 0}} the first.
+type aliasArgs []string
+
+func (a aliasArgs) String() string {
+	return strings.Join(a, " ")
+}
+
+// isBuiltinCommand reports whether name is a built-in command or alias,
+// which user-defined aliases can't replace
+func isBuiltinCommand(name string) bool {
+	if _, ok := commandAliases[name]; ok {
+		return true
+	}
+	return slices.Contains(completionCommands, name) ||
+		slices.Contains([]string{cmdComplete, "--help", "-h", "--version", "-v"}, name)
+}
+
+// expandUserAlias replaces a user-defined alias at the start of os.Args
+// with the command line it stands for, then strips any global flags that
+// line starts with. A config file that can't be read has no aliases;
+// NewManager reports the problem later.
+func expandUserAlias() {
+	cfg, _ := config.Load()
+	if _, ok := cfg.Aliases[os.Args[1]]; ok && isBuiltinCommand(os.Args[1]) {
+		util.Warn("alias %s is ignored, %s is a built-in command\n", os.Args[1], os.Args[1])
+	}
+
+	args, err := expandAliases(cfg.Aliases, os.Args[1:])
+	if err != nil {
+		usagef("%v", err)
+	}
+	if !slices.Equal(args, os.Args[1:]) {
+		os.Args = append(os.Args[:1], args...)
+		parseGlobalFlags()
+	}
+}
+
+// expandAliases expands the user-defined alias that args start with, and
+// then any alias that expansion starts with, failing when an alias comes
+// back around to itself. Args that don't start with an alias are returned
+// as they are.
+func expandAliases(aliases map[string]string, args []string) ([]string, error) {
+	var chain []string
+	for len(args) > 0 {
+		name := args[0]
+		definition, ok := aliases[name]
+		if !ok || isBuiltinCommand(name) {
+			break
+		}
+		if slices.Contains(chain, name) {
+			return nil, fmt.Errorf("alias loop: %s", strings.Join(append(chain, name), " -> "))
+		}
+		chain = append(chain, name)
+
+		expanded, err := expandAlias(name, definition, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		args = expanded
+	}
+	return args, nil
+}
+
+// templateAction matches a template action such as {{index . 0}}
+var templateAction = regexp.MustCompile(`{{.*?}}`)
+
+// expandAlias returns the command line of an alias definition for args.
+// Each word of the definition that holds a template is rendered with
+// aliasArgs into a single word. A definition without templates gets args
+// appended, as git aliases do.
+func expandAlias(name, definition string, args []string) ([]string, error) {
+	// Actions are set aside while splitting, since they may hold spaces
+	var actions []string
+	masked := templateAction.ReplaceAllStringFunc(definition, func(action string) string {
+		actions = append(actions, action)
+		return fmt.Sprintf("\x00%d\x00", len(actions)-1)
+	})
+	words, err := util.ShellSplit(masked)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alias %s: %w", name, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("invalid alias %s: empty command", name)
+	}
+
+	templated := false
+	for i, word := range words {
+		if !strings.Contains(word, "\x00") {
+			continue
+		}
+		templated = true
+		for j, action := range actions {
+			word = strings.ReplaceAll(word, fmt.Sprintf("\x00%d\x00", j), action)
+		}
+		tmpl, err := template.New(name).Parse(word)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias %s: %w", name, err)
+		}
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, aliasArgs(args)); err != nil {
+			return nil, fmt.Errorf("failed to expand alias %s: %w", name, err)
+		}
+		words[i] = buf.String()
+	}
+	if !templated {
+		words = append(words, args...)
+	}
+	return words, nil
+}
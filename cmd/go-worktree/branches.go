@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
+	"github.com/mdelgado509/go-worktree/pkg/worktree"
+)
+
+const cmdPruneBranches = "prune-branches"
+
+// handlePruneBranches deletes local ticket branches whose worktree is gone
+func handlePruneBranches() {
+	pruneCommand := flag.NewFlagSet(cmdPruneBranches, flag.ExitOnError)
+	var yes bool
+	pruneCommand.BoolVar(&yes, "y", false, "Don't ask for confirmation")
+	pruneCommand.BoolVar(&yes, "yes", false, "Don't ask for confirmation")
+
+	// Parse remaining args
+	parseFlags(pruneCommand, os.Args[2:])
+
+	wt := newRepoManager()
+	repo, err := wt.RepoName()
+	if err != nil {
+		fail(err)
+	}
+	branches, err := wt.DanglingBranches()
+	if err != nil {
+		fail(err)
+	}
+	worktree.RenderDanglingBranches(os.Stdout, repo, branches)
+	if len(branches) == 0 {
+		return
+	}
+
+	if !yes {
+		if !util.IsTerminal(os.Stdin) {
+			usagef("Refusing to delete branches without confirmation, pass --yes")
+		}
+		prompt := fmt.Sprintf("Delete these %d branches?", len(branches))
+		if !util.Confirm(os.Stdin, os.Stderr, prompt) {
+			fatalf("Aborted")
+		}
+	}
+
+	results := wt.DeleteBranches(branches)
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "  %s %s: %v\n", util.Colorize("failed", util.ColorRed), result.Branch, result.Err)
+			continue
+		}
+		util.Infof("  %s %s\n", util.Colorize("deleted", util.ColorGreen), result.Branch)
+	}
+	util.Infof("Deleted %d of %d branches\n", len(results)-failed, len(results))
+	if failed > 0 {
+		os.Exit(exitError)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mdelgado509/go-worktree/pkg/worktree"
+)
+
+// completionCommands are the subcommands offered for the first argument
+var completionCommands = []string{
+	cmdCreate, cmdDelete, cmdList, cmdStatus, cmdRecent, cmdOpen, cmdExec, cmdPR, cmdRename, cmdLock, cmdUnlock, cmdCD, cmdClean, cmdPrune, cmdPruneBranches, cmdShellInit, cmdDoctor, cmdConfig, cmdCompletion, "help", cmdVersion,
+}
+
+// ticketCommands are the subcommands (and aliases) that take a ticket ID
+var ticketCommands = []string{cmdCD, "switch", cmdDelete, "rm", "remove", "cleanup", cmdOpen, "edit", cmdExec, cmdRename, "move", "mv", cmdLock, cmdUnlock}
+
+const bashCompletion = `# bash completion for go-worktree
+_go_worktree() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    if [ "$COMP_CWORD" -eq 1 ]; then
+        COMPREPLY=($(compgen -W "%[1]s" -- "$cur"))
+        return
+    fi
+    case "${COMP_WORDS[1]}" in
+        %[2]s)
+            COMPREPLY=($(compgen -W "$(go-worktree %[3]s 2>/dev/null)" -- "$cur"))
+            ;;
+    esac
+}
+complete -F _go_worktree go-worktree
+`
+
+const zshCompletion = `#compdef go-worktree
+_go_worktree() {
+    if (( CURRENT == 2 )); then
+        compadd -- %[1]s
+        return
+    fi
+    case "$words[2]" in
+        %[2]s)
+            compadd -- ${(f)"$(go-worktree %[3]s 2>/dev/null)"}
+            ;;
+    esac
+}
+compdef _go_worktree go-worktree
+`
+
+const fishCompletion = `# fish completion for go-worktree
+complete -c go-worktree -f
+complete -c go-worktree -n '__fish_use_subcommand' -a '%[1]s'
+complete -c go-worktree -n '__fish_seen_subcommand_from %[2]s' -a '(go-worktree %[3]s 2>/dev/null)'
+`
+
+// handleCompletion prints a completion script for the requested shell
+func handleCompletion() {
+	if len(os.Args) < 3 {
+		usagef("Shell required: bash, zsh, or fish")
+	}
+
+	commands := strings.Join(completionCommands, " ")
+	switch os.Args[2] {
+	case "bash":
+		fmt.Printf(bashCompletion, commands, strings.Join(ticketCommands, "|"), cmdComplete)
+	case "zsh":
+		fmt.Printf(zshCompletion, commands, strings.Join(ticketCommands, "|"), cmdComplete)
+	case "fish":
+		fmt.Printf(fishCompletion, commands, strings.Join(ticketCommands, " "), cmdComplete)
+	default:
+		usagef("Unsupported shell: %s (expected bash, zsh, or fish)", os.Args[2])
+	}
+}
+
+// handleComplete prints the ticket IDs for the current repository, one per
+// line. It is called by the completion scripts and stays silent on errors.
+func handleComplete() {
+	wt := worktree.NewManager()
+	if inside, _ := wt.IsInsideRepo(); !inside {
+		return
+	}
+	tickets, err := wt.Tickets()
+	if err != nil {
+		return
+	}
+	for _, ticket := range tickets {
+		fmt.Println(ticket)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mdelgado509/go-worktree/internal/config"
+	"github.com/mdelgado509/go-worktree/internal/git"
+	"github.com/mdelgado509/go-worktree/internal/util"
+	"github.com/mdelgado509/go-worktree/pkg/worktree"
+)
+
+const cmdConfig = "config"
+
+// settingEnv maps settings to the environment variables that override them
+var settingEnv = map[string]string{
+	"base_path": worktree.BasePathEnv,
+	"git_path":  git.BinaryEnv,
+}
+
+// handleConfig reads and writes settings in the user's config file
+func handleConfig() {
+	if len(os.Args) < 3 {
+		usagef("Subcommand required: get, set, or list")
+	}
+
+	path, err := config.DefaultPath()
+	if err != nil {
+		fail(err)
+	}
+
+	switch os.Args[2] {
+	case "get":
+		if len(os.Args) != 4 {
+			usagef("Usage: go-worktree config get KEY")
+		}
+		setting, err := config.LookupSetting(os.Args[3])
+		if err != nil {
+			usagef("%v", err)
+		}
+		cfg, err := config.LoadFile(path)
+		if err != nil {
+			fail(err)
+		}
+
+		value := setting.Get(cfg)
+		if value == "" {
+			// Like git config, an unset key prints nothing and fails
+			if setting.Default != "" {
+				util.Infof("%s is not set (default: %s)\n", setting.Key, setting.Default)
+			}
+			os.Exit(exitError)
+		}
+		fmt.Println(value)
+
+	case "set":
+		if len(os.Args) != 5 {
+			usagef("Usage: go-worktree config set KEY VALUE")
+		}
+		key, value := os.Args[3], os.Args[4]
+		if _, err := config.LookupSetting(key); err != nil {
+			usagef("%v", err)
+		}
+		if err := config.Set(path, key, value); err != nil {
+			fail(err)
+		}
+		util.Infof("Set %s to %s in %s\n", util.Colorize(key, util.ColorGreen), util.Colorize(value, util.ColorBlue), path)
+
+	case "list":
+		cfg, err := config.LoadFile(path)
+		if err != nil {
+			fail(err)
+		}
+
+		fmt.Printf("Config file: %s\n", path)
+		for _, setting := range config.Settings {
+			value := setting.Get(cfg)
+			switch {
+			case value != "":
+				value = util.Colorize(value, util.ColorBlue)
+			case setting.Default != "":
+				value = fmt.Sprintf("(default: %s)", setting.Default)
+			default:
+				value = "(not set)"
+			}
+			if name, ok := settingEnv[setting.Key]; ok && os.Getenv(name) != "" {
+				value += util.Colorize(fmt.Sprintf(" (overridden by %s=%s)", name, os.Getenv(name)), util.ColorYellow)
+			}
+			fmt.Printf("  %-20s %s\n", setting.Key, value)
+		}
+
+	default:
+		usagef("Unknown config subcommand %q, expected get, set, or list", os.Args[2])
+	}
+}
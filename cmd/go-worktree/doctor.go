@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mdelgado509/go-worktree/internal/config"
+	"github.com/mdelgado509/go-worktree/internal/git"
+	"github.com/mdelgado509/go-worktree/internal/util"
+	"github.com/mdelgado509/go-worktree/pkg/worktree"
+)
+
+const cmdDoctor = "doctor"
+
+// doctorCheck is the outcome of one environment check
+type doctorCheck struct {
+	name   string
+	ok     bool
+	detail string
+	// critical checks make doctor exit non-zero when they fail
+	critical bool
+}
+
+// handleDoctor prints a checklist of common environment problems and exits
+// non-zero if a critical check fails
+func handleDoctor() {
+	// A broken config file is reported by worktree.NewManager below
+	cfg, _ := config.Load()
+	binary := git.ResolveBinary(cfg.GitPath)
+
+	checks := []doctorCheck{checkGit(binary)}
+	if checks[0].ok {
+		checks = append(checks, checkRepo(binary))
+	}
+	checks = append(checks, checkBasePath(worktree.NewManager().BasePath()), checkEditor())
+
+	failed := false
+	for _, c := range checks {
+		mark, color := "✓", util.ColorGreen
+		if !c.ok {
+			mark, color = "!", util.ColorYellow
+			if c.critical {
+				mark, color = "✗", util.ColorRed
+				failed = true
+			}
+		}
+		fmt.Printf("  %s %s: %s\n", util.Colorize(mark, color), c.name, c.detail)
+	}
+
+	if failed {
+		os.Exit(exitError)
+	}
+}
+
+// checkGit verifies the git executable binary can be found and reports
+// its version
+func checkGit(binary string) doctorCheck {
+	check := doctorCheck{name: "git", critical: true}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		check.detail = "not found on PATH, install git to use go-worktree"
+		if binary != git.DefaultBinary {
+			check.detail = fmt.Sprintf("%s not found, check git_path and $%s", binary, git.BinaryEnv)
+		}
+		return check
+	}
+
+	client := git.NewClient()
+	client.SetBinary(binary)
+	version, err := client.Version(ctx)
+	if err != nil {
+		check.detail = fmt.Sprintf("%s could not be run: %v", path, err)
+		return check
+	}
+	check.ok = true
+	check.detail = fmt.Sprintf("version %s (%s)", version, path)
+	return check
+}
+
+// checkRepo reports whether the current directory is inside a repository.
+// Not being in one is expected when running doctor from elsewhere.
+func checkRepo(binary string) doctorCheck {
+	check := doctorCheck{name: "repository"}
+	client := git.NewClient()
+	client.SetBinary(binary)
+	inside, err := client.IsInsideRepo(ctx)
+	switch {
+	case err != nil:
+		check.detail = err.Error()
+	case !inside:
+		check.detail = "current directory is not inside a git repository"
+	default:
+		check.ok = true
+		check.detail = "current directory is inside a git repository"
+	}
+	return check
+}
+
+// checkBasePath verifies the worktree base path is writable, or that its
+// nearest existing parent is so it can be created on first use
+func checkBasePath(basePath string) doctorCheck {
+	check := doctorCheck{name: "base path", critical: true}
+
+	dir := basePath
+	for {
+		info, err := os.Stat(dir)
+		if err == nil && !info.IsDir() {
+			check.detail = fmt.Sprintf("%s is not a directory", dir)
+			return check
+		}
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) || filepath.Dir(dir) == dir {
+			check.detail = fmt.Sprintf("cannot access %s: %v", dir, err)
+			return check
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	probe, err := os.CreateTemp(dir, ".go-worktree-doctor-")
+	if err != nil {
+		check.detail = fmt.Sprintf("%s is not writable: %v", dir, err)
+		return check
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	check.ok = true
+	if dir == basePath {
+		check.detail = fmt.Sprintf("%s exists and is writable", basePath)
+	} else {
+		check.detail = fmt.Sprintf("%s will be created on first use", basePath)
+	}
+	return check
+}
+
+// checkEditor reports whether $EDITOR is set for the open command
+func checkEditor() doctorCheck {
+	check := doctorCheck{name: "editor"}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		check.ok = true
+		check.detail = fmt.Sprintf("$EDITOR is %s", editor)
+		return check
+	}
+	check.detail = "$EDITOR is not set, open will need --editor or the editor config option"
+	return check
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+const cmdExec = "exec"
+
+// handleExec runs a command inside a worktree and exits with its status
+func handleExec() {
+	// Everything after the ticket belongs to the command, so flags are not
+	// parsed; the -- separator is optional
+	args := os.Args[2:]
+	if len(args) < 1 {
+		usagef("Ticket ID required")
+	}
+	ticket, command := args[0], args[1:]
+	if len(command) > 0 && command[0] == "--" {
+		command = command[1:]
+	}
+	if len(command) == 0 {
+		usagef("Command required, as in: go-worktree exec %s -- make test", ticket)
+	}
+
+	wt := newRepoManager()
+	err := wt.Exec(ticket, command)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		// The command has already reported its own failure
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		fail(err)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+)
+
+// jsonOutput is set by the global --json flag
+var jsonOutput bool
+
+// jsonCommands are the commands the global --json flag applies to
+var jsonCommands = []string{cmdCreate, cmdDelete, cmdList, cmdCD, cmdStatus, cmdVersion}
+
+// command is the canonical name of the command being run, for the JSON
+// envelope
+var command string
+
+// jsonEnvelope is the document every command prints with the global
+// --json flag. Error is null on success; on failure Result holds whatever
+// was known by then, or null.
+type jsonEnvelope struct {
+	Command string  `json:"command"`
+	Result  any     `json:"result"`
+	Error   *string `json:"error"`
+}
+
+// deleteResult is the result of delete under --json
+type deleteResult struct {
+	Ticket string `json:"ticket"`
+	Path   string `json:"path"`
+}
+
+// cdResult is the result of cd under --json. Path is left out when the
+// ticket has no worktree.
+type cdResult struct {
+	Ticket string `json:"ticket"`
+	Path   string `json:"path,omitempty"`
+}
+
+// errJSONNotFound is the error cd reports under --json when the ticket has
+// no worktree, so scripts can tell it apart without matching a message
+var errJSONNotFound = errors.New("not_found")
+
+// checkJSONCommand exits with a usage error when the global --json flag is
+// given to a command without JSON output
+func checkJSONCommand() {
+	if jsonOutput && !slices.Contains(jsonCommands, command) {
+		usagef("--json only applies to %s", strings.Join(jsonCommands, ", "))
+	}
+}
+
+// writeJSON prints result and err to stdout in the envelope for the
+// current command, exiting with the exit code for err when it is set
+func writeJSON(result any, err error) {
+	printJSON(result, err)
+	if err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// printJSON prints result and err to stdout in the envelope for the
+// current command
+func printJSON(result any, err error) {
+	envelope := jsonEnvelope{Command: command, Result: result}
+	if err != nil {
+		message := err.Error()
+		envelope.Error = &message
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(envelope)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"os"
+)
+
+const (
+	cmdLock   = "lock"
+	cmdUnlock = "unlock"
+)
+
+// handleLock locks a worktree so git worktree prune leaves it alone
+func handleLock() {
+	lockCommand := flag.NewFlagSet(cmdLock, flag.ExitOnError)
+	reason := lockCommand.String("reason", "", "Why the worktree is locked, shown by git")
+
+	// Parse remaining args, allowing flags after the ticket
+	args := parseFlags(lockCommand, os.Args[2:])
+	if len(args) < 1 {
+		usagef("Ticket ID required")
+	}
+
+	wt := newRepoManager()
+	if err := wt.Lock(args[0], *reason); err != nil {
+		fail(err)
+	}
+}
+
+// handleUnlock removes the lock from a worktree
+func handleUnlock() {
+	if len(os.Args) < 3 {
+		usagef("Ticket ID required")
+	}
+
+	wt := newRepoManager()
+	if err := wt.Unlock(os.Args[2]); err != nil {
+		fail(err)
+	}
+}
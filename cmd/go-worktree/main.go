@@ -2,21 +2,35 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
 
 	"github.com/mdelgado509/go-worktree/internal/util"
-	"github.com/mdelgado509/go-worktree/internal/worktree"
+	"github.com/mdelgado509/go-worktree/pkg/worktree"
 )
 
 // Command constants define the available commands
 const (
-	cmdCreate = "create"
-	cmdDelete = "delete"
-	cmdList   = "list"
-	cmdCD     = "cd"
-	version   = "1.0.0"
+	cmdCreate     = "create"
+	cmdDelete     = "delete"
+	cmdList       = "list"
+	cmdCD         = "cd"
+	cmdPrune      = "prune"
+	cmdStatus     = "status"
+	cmdRecent     = "recent"
+	cmdOpen       = "open"
+	cmdRename     = "rename"
+	cmdClean      = "clean"
+	cmdCompletion = "completion"
+	cmdComplete   = "__complete" // hidden, used by completion scripts
 )
 
 // commandAliases maps alternative command names to canonical commands
@@ -27,38 +41,67 @@ var commandAliases = map[string]string{
 	"cleanup": cmdDelete,
 	"remove":  cmdDelete,
 	"ls":      cmdList,
+	"st":      cmdStatus,
 	"switch":  cmdCD,
+	"edit":    cmdOpen,
+	"move":    cmdRename,
+	"mv":      cmdRename,
 }
 
+// ctx is cancelled on Ctrl-C or SIGTERM so running git commands are
+// aborted cleanly and a half-created worktree is removed
+var ctx = context.Background()
+
 func main() {
+	var stop context.CancelFunc
+	ctx, stop = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Show usage if no arguments are provided
 	if len(os.Args) < 2 {
 		printUsage()
 		return
 	}
 
+	// Strip global flags that precede the command, and expand the command
+	// if it is a user-defined alias
+	parseGlobalFlags()
+	if len(os.Args) >= 2 {
+		expandUserAlias()
+	}
+	if len(os.Args) < 2 {
+		printUsage()
+		return
+	}
+
 	// Get the command and resolve aliases
 	cmdArg := os.Args[1]
 
-	// Handle special case for help and version
+	// Handle special case for help
 	if cmdArg == "help" || cmdArg == "--help" || cmdArg == "-h" {
 		printUsage()
 		return
 	}
 
-	if cmdArg == "version" || cmdArg == "--version" || cmdArg == "-v" {
-		fmt.Printf("go-worktree version %s\n", version)
-		return
-	}
-
 	// Resolve command alias
 	cmd, exists := commandAliases[cmdArg]
 	if !exists {
 		cmd = cmdArg
 	}
+	if cmdArg == "--version" || cmdArg == "-v" {
+		cmd = cmdVersion
+	}
+	command = cmd
+	checkJSONCommand()
+
+	if dryRun && cmd != cmdCreate && cmd != cmdDelete {
+		usagef("--dry-run only applies to create and delete")
+	}
 
 	// Route to appropriate handler
 	switch cmd {
+	case cmdVersion:
+		handleVersion()
 	case cmdCreate:
 		handleCreate()
 	case cmdDelete:
@@ -67,113 +110,855 @@ func main() {
 		handleList()
 	case cmdCD:
 		handleCD()
+	case cmdPrune:
+		handlePrune()
+	case cmdPruneBranches:
+		handlePruneBranches()
+	case cmdStatus:
+		handleStatus()
+	case cmdRecent:
+		handleRecent()
+	case cmdOpen:
+		handleOpen()
+	case cmdExec:
+		handleExec()
+	case cmdPR:
+		handlePR()
+	case cmdRename:
+		handleRename()
+	case cmdLock:
+		handleLock()
+	case cmdUnlock:
+		handleUnlock()
+	case cmdClean:
+		handleClean()
+	case cmdShellInit:
+		handleShellInit()
+	case cmdDoctor:
+		handleDoctor()
+	case cmdConfig:
+		handleConfig()
+	case cmdCompletion:
+		handleCompletion()
+	case cmdComplete:
+		handleComplete()
 	default:
-		fmt.Fprintf(os.Stderr, "%sUnknown command: %s%s\n",
-			util.ColorRed, cmdArg, util.ColorReset)
+		fmt.Fprintln(os.Stderr, util.Colorize("Unknown command: "+cmdArg, util.ColorRed))
 		printUsage()
-		os.Exit(1)
+		os.Exit(exitUsage)
+	}
+}
+
+// dryRun is set by the global --dry-run flag
+var dryRun bool
+
+// parseGlobalFlags consumes flags that apply to every command when they
+// appear before the command name, leaving os.Args as if they were absent
+func parseGlobalFlags() {
+	args := os.Args[1:]
+	for len(args) > 0 {
+		switch args[0] {
+		case "-q", "--quiet":
+			util.SetVerbosity(util.VerbosityQuiet)
+		case "-V", "--verbose":
+			util.SetVerbosity(util.VerbosityVerbose)
+		case "--dry-run":
+			dryRun = true
+		case "--json":
+			jsonOutput = true
+		default:
+			os.Args = append(os.Args[:1], args...)
+			return
+		}
+		args = args[1:]
+	}
+	os.Args = os.Args[:1]
+}
+
+// fatalf prints an error message to stderr and exits with a failure status
+func fatalf(format string, args ...any) {
+	exitf(exitError, format, args...)
+}
+
+// usagef prints an error message about invalid arguments to stderr and
+// exits with the usage status
+func usagef(format string, args ...any) {
+	exitf(exitUsage, format, args...)
+}
+
+// exitf prints an error message to stderr and exits with code. With the
+// global --json flag the message is printed in the JSON envelope instead.
+func exitf(code int, format string, args ...any) {
+	if jsonOutput {
+		printJSON(nil, fmt.Errorf(format, args...))
+		os.Exit(code)
+	}
+	util.Errorf(format+"\n", args...)
+	os.Exit(code)
+}
+
+// parseFlags parses flags that may appear before or after positional
+// arguments and returns the positional arguments in order
+func parseFlags(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			usagef("%v", err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	return positional
+}
+
+// Process exit codes
+const (
+	exitError = 1
+	// exitUsage matches the status the flag package exits with on bad flags
+	exitUsage     = 2
+	exitNotInRepo = 3
+	exitNotFound  = 4
+	exitExists    = 5
+	exitGitFailed = 6
+	exitDirty     = 7
+	// exitInterrupted follows the shell convention of 128 + SIGINT
+	exitInterrupted = 130
+)
+
+// exitCode picks the process exit code for err
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, worktree.ErrNotARepo):
+		return exitNotInRepo
+	case errors.Is(err, worktree.ErrWorktreeNotFound), errors.Is(err, worktree.ErrBranchNotFound):
+		return exitNotFound
+	case errors.Is(err, worktree.ErrWorktreeExists), errors.Is(err, worktree.ErrBranchCheckedOut):
+		return exitExists
+	case errors.Is(err, worktree.ErrWorktreeDirty):
+		return exitDirty
+	case errors.Is(err, worktree.ErrGitFailed), errors.Is(err, worktree.ErrWorktreeLocked):
+		// Delete reports a locked worktree git refused to remove by itself
+		return exitGitFailed
+	default:
+		return exitError
+	}
+}
+
+// fail prints err to stderr, or in the JSON envelope with the global
+// --json flag, and exits with the exit code for its kind
+func fail(err error) {
+	if jsonOutput {
+		writeJSON(nil, err)
 	}
+	util.Errorf("%v\n", err)
+	os.Exit(exitCode(err))
+}
+
+// newRepoManager creates a worktree manager that prints progress messages
+// to stderr, exiting with guidance when the current directory is not inside
+// a git repository. With the global --json flag progress messages are left
+// out altogether.
+func newRepoManager() *worktree.Manager {
+	wt, err := openRepoManager()
+	if jsonOutput {
+		if err != nil {
+			fail(err)
+		}
+		return wt
+	}
+	if errors.Is(err, worktree.ErrNotARepo) {
+		util.Errorf("not inside a git repository\n")
+		fmt.Fprintln(os.Stderr, "Run go-worktree from inside the repository whose worktrees you want to manage.")
+		os.Exit(exitNotInRepo)
+	}
+	if err != nil {
+		fail(err)
+	}
+	wt.SetOutput(util.InfoWriter())
+	wt.SetAnimate(animateOn(os.Stderr))
+	return wt
+}
+
+// animateOn reports whether a spinner can be drawn on f. Verbose and
+// dry-run output would be mixed into the spinner's line.
+func animateOn(f *os.File) bool {
+	return util.IsTerminal(f) && !util.Quiet() && !util.Verbose() && !dryRun
+}
+
+// openRepoManager creates a worktree manager that prints nothing, failing
+// with ErrNotARepo when the current directory is not inside a git
+// repository
+func openRepoManager() (*worktree.Manager, error) {
+	wt := worktree.NewManager()
+	inside, err := wt.IsInsideRepo()
+	if err != nil {
+		return nil, err
+	}
+	if !inside {
+		return nil, worktree.ErrNotARepo
+	}
+	wt.SetContext(ctx)
+	if dryRun {
+		// Keep stdout for the JSON document
+		out := os.Stdout
+		if jsonOutput {
+			out = os.Stderr
+		}
+		wt.SetDryRun(out)
+	}
+	return wt, nil
 }
 
 func printUsage() {
 	fmt.Println("Golang Git Worktree Manager - Streamlined workflow")
 	fmt.Println("\nUsage:")
-	fmt.Println("  go-worktree create|add TICKET-ID [BASE-BRANCH]  Create a new worktree (default: main)")
-	fmt.Println("  go-worktree delete|rm TICKET-ID [-d]            Delete a worktree (-d to delete branch)")
-	fmt.Println("  go-worktree list|ls                             List all your worktrees")
-	fmt.Println("  go-worktree cd|switch TICKET-ID                 Print command to change to worktree")
+	fmt.Println("  go-worktree [-q|--quiet] COMMAND ...            Suppress informational output")
+	fmt.Println("  go-worktree [-V|--verbose] COMMAND ...          Echo each git command to stderr")
+	fmt.Println("  go-worktree --dry-run create|delete ...         Print the commands that would run without running them")
+	fmt.Println("  go-worktree --json create|delete|list|cd|status|version ...  Print the result in a JSON envelope")
+	fmt.Println("  go-worktree create|add TICKET-ID [BASE-BRANCH]  Create a new worktree (default: origin's default branch)")
+	fmt.Println("  go-worktree create ID-1 ID-2 ID-3 [--base BRANCH]  Create several worktrees from one base branch")
+	fmt.Println("  go-worktree create TICKET-ID --existing         Check out an existing branch into a worktree")
+	fmt.Println("  go-worktree create TICKET-ID --branch-only      Create the branch without a worktree")
+	fmt.Println("  go-worktree create TICKET-ID --branch NAME      Use NAME as the branch instead of the ticket ID")
+	fmt.Println("  go-worktree create TICKET-ID --name NAME        Use NAME as the directory, which other commands take, instead of the ticket ID")
+	fmt.Println("  go-worktree create TICKET-ID --remote NAME      Fetch the base branch from NAME (default: origin)")
+	fmt.Println("  go-worktree create TICKET-ID --track           Track REMOTE/BASE-BRANCH as upstream")
+	fmt.Println("  go-worktree create TICKET-ID --track-remote    Check out and track REMOTE/TICKET-ID, pushed by someone else")
+	fmt.Println("  go-worktree create TICKET-ID --from-current     Branch from the current branch without fetching")
+	fmt.Println("  go-worktree create TICKET-ID --base-from-tracking  Branch from the upstream of the current branch")
+	fmt.Println("  go-worktree create TICKET-ID --branch-from REF  Start the new branch at a tag or commit")
+	fmt.Println("  go-worktree create TICKET-ID --no-fetch         Skip fetching the base branch, for offline work")
+	fmt.Println("  go-worktree create TICKET-ID --depth N          Fetch only the last N commits of the base branch")
+	fmt.Println("  go-worktree create TICKET-ID --copy-all         Copy tracked copy_on_create files too, not only ignored ones")
+	fmt.Println("  go-worktree create TICKET-ID --fetch-retries N  Retry a fetch that hit a network error N times (default: 2)")
+	fmt.Println("  go-worktree create TICKET-ID --json             Print the path, branch, and base as JSON")
+	fmt.Println("  go-worktree create TICKET-ID --cd               Create, then print the cd command (eval \"$(...)\")")
+	fmt.Println("  go-worktree create TICKET-ID --no-hook          Skip the configured post-create hook")
+	fmt.Println("  go-worktree delete|rm TICKET-ID [-d] [-f]       Delete a worktree (-d to delete branch, -f to force)")
+	fmt.Println("  go-worktree delete ID-1 ID-2 ID-3 [-d]          Delete several worktrees, carrying on past failures")
+	fmt.Println("  go-worktree delete TICKET-ID -d --force-protected  Allow deleting a protected branch such as main")
+	fmt.Println("  go-worktree delete TICKET-ID --keep-dir         Unregister the worktree but keep its files")
+	fmt.Println("  go-worktree delete BRANCH --by-branch           Delete the worktree that has BRANCH checked out")
+	fmt.Println("  go-worktree delete @                            Use @ for the worktree you are in (cd, delete, open, exec)")
+	fmt.Println("  go-worktree list|ls [--json|--porcelain] [--sort KEY [--reverse]] [--size] [--all] [--stale] [--no-header]  List your worktrees (--all adds unmanaged ones, --stale marks merged ones)")
+	fmt.Println("  go-worktree list --global                       List the worktrees of every repository, grouped by repo")
+	fmt.Println("  go-worktree list -l|--long                      Add each worktree's base and creation time")
+	fmt.Println("  go-worktree status|st                           Show clean/dirty state of each worktree")
+	fmt.Println("  go-worktree recent [--limit N]                  List worktrees by last modified, newest first")
+	fmt.Println("  go-worktree cd|switch [TICKET-ID]               Print command to change to worktree (prompts if omitted)")
+	fmt.Println("  go-worktree cd TICKET-ID --shell powershell|cmd Print the command for PowerShell or cmd on Windows")
+	fmt.Println("  go-worktree cd TICKET-ID --path-only            Print just the worktree path, for scripts")
+	fmt.Println("  go-worktree open|edit TICKET-ID [--editor CMD]  Open a worktree in your editor")
+	fmt.Println("  go-worktree exec TICKET-ID -- COMMAND [ARGS...]  Run a command inside a worktree and exit with its status")
+	fmt.Println("  go-worktree pr [--base BRANCH]                  Open GitHub to create a pull request for the current branch")
+	fmt.Println("  go-worktree rename|mv OLD-ID NEW-ID             Move a worktree and rename its branch")
+	fmt.Println("  go-worktree lock TICKET-ID [--reason TEXT]      Lock a worktree so git worktree prune keeps it")
+	fmt.Println("  go-worktree unlock TICKET-ID                    Remove the lock from a worktree")
+	fmt.Println("  go-worktree clean [-d] [--yes]                  Delete every worktree for this repo (-d to delete branches)")
+	fmt.Println("  go-worktree prune [--dry-run]                   Remove directories with no registered worktree")
+	fmt.Println("  go-worktree prune-branches [--yes]              Delete ticket branches whose worktree was removed")
+	fmt.Println("  go-worktree shellinit [--shell bash|zsh|fish]   Print the gwt shell function")
+	fmt.Println("  go-worktree config get|set|list [KEY] [VALUE]   Read or change settings in the config file")
+	fmt.Println("  go-worktree ALIAS [ARGS...]                     Run a command defined under aliases in the config file")
+	fmt.Println("  go-worktree doctor                              Check git, the base path, and your editor setup")
+	fmt.Println("  go-worktree completion bash|zsh|fish            Print a shell completion script")
 	fmt.Println("  go-worktree help|--help                         Show this help message")
-	fmt.Println("  go-worktree version|--version                   Show version information")
+	fmt.Println("  go-worktree version|--version [--json]          Show version and build information")
 	fmt.Println("\nExamples:")
 	fmt.Println("  go-worktree create ABC-746                      Create worktree for ticket ABC-746")
 	fmt.Println("  go-worktree create ABC-746 develop              Create from develop branch")
 	fmt.Println("  go-worktree delete ABC-746 -d                   Delete worktree and branch")
-	fmt.Println("  eval $(go-worktree cd ABC-746)                  Switch to ABC-746 worktree")
+	fmt.Println("  eval \"$(go-worktree cd ABC-746)\"                Switch to ABC-746 worktree")
 }
 
 // handleCreate handles the create command
 func handleCreate() {
 	createCommand := flag.NewFlagSet(cmdCreate, flag.ExitOnError)
-	baseBranch := createCommand.String("base", "main", "Base branch to create from")
+	baseBranch := createCommand.String("base", "", "Base branch to create from (default: config, then the remote's default branch, then main)")
+	existing := createCommand.Bool("existing", false, "Check out an existing branch instead of creating one")
+	noHook := createCommand.Bool("no-hook", false, "Skip the configured post-create hook")
+	branch := createCommand.String("branch", "", "Branch name to use instead of the prefixed ticket ID")
+	name := createCommand.String("name", "", "Directory name to use instead of the ticket ID")
+	remote := createCommand.String("remote", "", "Remote to fetch the base branch from (default: config or origin)")
+	track := createCommand.Bool("track", false, "Set the new branch's upstream to the remote base branch")
+	trackRemote := createCommand.Bool("track-remote", false, "Fetch the remote branch named after the ticket and track it")
+	fromCurrent := createCommand.Bool("from-current", false, "Base the new branch on the currently checked out branch")
+	baseFromTracking := createCommand.Bool("base-from-tracking", false, "Base the new branch on the upstream of the current branch")
+	branchFrom := createCommand.String("branch-from", "", "Start the new branch at a commit, tag, or other ref")
+	noFetch := createCommand.Bool("no-fetch", false, "Skip fetching the base branch and use the local copy")
+	branchOnly := createCommand.Bool("branch-only", false, "Create the branch without a worktree, to check out later with --existing")
+	depth := createCommand.Int("depth", 0, "Fetch only the last N commits of the base branch (default: full history)")
+	copyAll := createCommand.Bool("copy-all", false, "Copy every copy_on_create match, including files tracked by git")
+	fetchRetries := createCommand.Int("fetch-retries", worktree.DefaultFetchRetries, "Times to retry fetching the base branch after a network error")
+	jsonFlag := createCommand.Bool("json", false, "Print the result in a JSON envelope instead of progress messages, like the global --json")
+	cdAfter := createCommand.Bool("cd", false, "Print a command that changes to the new worktree, for eval")
 
-	// Parse remaining args
-	err := createCommand.Parse(os.Args[2:])
+	// Parse remaining args, allowing flags after the ticket
+	args := parseFlags(createCommand, os.Args[2:])
+	if len(args) < 1 {
+		usagef("Ticket ID required")
+	}
+
+	// Allow the base branch as a second positional arg for convenience.
+	// Three or more args, or any args with --base, are all tickets.
+	tickets := args
+	if len(args) == 2 && *baseBranch == "" && !*baseFromTracking {
+		tickets, *baseBranch = args[:1], args[1]
+	}
+
+	opts := worktree.CreateOptions{
+		BaseBranch:       *baseBranch,
+		Existing:         *existing,
+		NoHook:           *noHook,
+		Branch:           *branch,
+		Name:             *name,
+		Remote:           *remote,
+		Track:            *track,
+		TrackRemote:      *trackRemote,
+		FromCurrent:      *fromCurrent,
+		BaseFromTracking: *baseFromTracking,
+		BranchFrom:       *branchFrom,
+		Depth:            *depth,
+		FetchRetries:     *fetchRetries,
+		NoFetch:          *noFetch,
+		CopyAll:          *copyAll,
+		BranchOnly:       *branchOnly,
+	}
+	// The command's own --json is the same as the global flag
+	jsonOutput = jsonOutput || *jsonFlag
+	if jsonOutput && *cdAfter {
+		usagef("--cd cannot be combined with --json")
+	}
+	if *depth < 0 {
+		usagef("Invalid --depth %d, expected a number of commits, or 0 for the full history", *depth)
+	}
+	if *branchOnly && *cdAfter {
+		usagef("--cd cannot be combined with --branch-only, which makes no worktree")
+	}
+	if jsonOutput {
+		createEnvelope(tickets, opts)
+		return
+	}
+	if *cdAfter {
+		if len(tickets) > 1 {
+			usagef("--cd takes a single ticket ID")
+		}
+		createCD(tickets[0], opts)
+		return
+	}
+
+	wt := newRepoManager()
+	if len(tickets) == 1 {
+		if _, err := wt.Create(tickets[0], opts); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	results, err := wt.CreateAll(tickets, opts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", util.ColorRed, err, util.ColorReset)
-		os.Exit(1)
+		fail(err)
 	}
 
-	args := createCommand.Args()
-	if len(args) < 1 {
-		fmt.Fprintf(os.Stderr, "%sError: Ticket ID required%s\n", util.ColorRed, util.ColorReset)
-		os.Exit(1)
+	failed := 0
+	util.Infof("\nSummary:\n")
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "  %s %s: %v\n", util.Colorize("failed", util.ColorRed), result.Ticket, result.Err)
+			continue
+		}
+		util.Infof("  %s %s\n", util.Colorize("created", util.ColorGreen), result.Ticket)
 	}
+	noun := "worktrees"
+	if *branchOnly {
+		noun = "branches"
+	}
+	util.Infof("Created %d of %d %s\n", len(results)-failed, len(results), noun)
+	if failed > 0 {
+		os.Exit(exitError)
+	}
+}
 
-	ticket := args[0]
-	// Allow overriding base branch as positional arg for convenience
-	if len(args) > 1 {
-		*baseBranch = args[1]
+// createCD creates the worktree and prints the command that changes to
+// it, like cd does. The dry-run commands go to stderr with the progress
+// messages so that stdout only carries the command for eval.
+func createCD(ticket string, opts worktree.CreateOptions) {
+	wt := newRepoManager()
+	if dryRun {
+		wt.SetDryRun(os.Stderr)
 	}
 
-	wt := worktree.NewManager()
-	if err := wt.Create(ticket, *baseBranch); err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", util.ColorRed, err, util.ColorReset)
-		os.Exit(1)
+	result, err := wt.Create(ticket, opts)
+	if err != nil {
+		fail(err)
+	}
+	if dryRun {
+		// There is no worktree to change to
+		return
 	}
+	fmt.Println(shellCD(defaultCDShell(runtime.GOOS), result.Path))
+}
+
+// createEnvelope creates the worktrees for the global --json flag. The
+// result is an object for one ticket or an array for several, where each
+// failure is also reported in the worktree's "error" field.
+func createEnvelope(tickets []string, opts worktree.CreateOptions) {
+	wt := newRepoManager()
+	if len(tickets) == 1 {
+		result, err := wt.Create(tickets[0], opts)
+		writeJSON(result, err)
+		return
+	}
+
+	results, err := wt.CreateAll(tickets, opts)
+	if err != nil {
+		fail(err)
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		err = fmt.Errorf("failed to create %d of %d worktrees", failed, len(results))
+	}
+	writeJSON(results, err)
 }
 
 // handleDelete handles the delete command
 func handleDelete() {
 	deleteCommand := flag.NewFlagSet(cmdDelete, flag.ExitOnError)
 	deleteBranch := deleteCommand.Bool("d", false, "Delete branch as well")
+	var force bool
+	deleteCommand.BoolVar(&force, "f", false, "Remove the worktree even if it has uncommitted changes")
+	deleteCommand.BoolVar(&force, "force", false, "Remove the worktree even if it has uncommitted changes")
+	forceProtected := deleteCommand.Bool("force-protected", false, "Allow -d to delete a protected branch")
+	keepDir := deleteCommand.Bool("keep-dir", false, "Unregister the worktree but keep its files under the base path")
+	byBranch := deleteCommand.Bool("by-branch", false, "Find the worktree by the branch checked out in it instead of the ticket")
+
+	// Parse remaining args, allowing flags after the tickets
+	args := parseFlags(deleteCommand, os.Args[2:])
+	if len(args) < 1 {
+		usagef("Ticket ID required")
+	}
+	if *byBranch && len(args) > 1 {
+		usagef("--by-branch takes a single branch")
+	}
+	opts := worktree.DeleteOptions{
+		DeleteBranch:   *deleteBranch,
+		Force:          force,
+		ForceProtected: *forceProtected,
+		KeepDir:        *keepDir,
+	}
+	if len(args) > 1 {
+		deleteTickets(args, opts)
+		return
+	}
+
+	ticket := args[0]
+	wt := newRepoManager()
+	if *byBranch {
+		var err error
+		if ticket, err = wt.TicketForBranch(args[0]); err != nil {
+			fail(err)
+		}
+	}
+	if jsonOutput {
+		// Look the worktree up first, since it is gone afterwards
+		result := deleteResult{Ticket: ticket}
+		var err error
+		if result.Path, err = wt.ExistingPath(ticket); err != nil {
+			fail(err)
+		}
+		if result.Ticket, err = wt.ResolveTicket(ticket); err != nil {
+			fail(err)
+		}
+		writeJSON(result, wt.Delete(ticket, opts))
+		return
+	}
+	if err := wt.Delete(ticket, opts); err != nil {
+		fail(err)
+	}
+}
+
+// deleteTickets removes the worktrees of several tickets, carrying on past
+// failures, and prints a summary
+func deleteTickets(tickets []string, opts worktree.DeleteOptions) {
+	wt := newRepoManager()
+	results := wt.DeleteTickets(tickets, opts)
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if jsonOutput {
+		var err error
+		if failed > 0 {
+			err = fmt.Errorf("failed to delete %d of %d worktrees", failed, len(results))
+		}
+		writeJSON(results, err)
+		return
+	}
+
+	util.Infof("\nSummary:\n")
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s: %v\n", util.Colorize("failed", util.ColorRed), result.Ticket, result.Err)
+			continue
+		}
+		util.Infof("  %s %s\n", util.Colorize("removed", util.ColorGreen), result.Ticket)
+	}
+	util.Infof("Removed %d of %d worktrees\n", len(results)-failed, len(results))
+	if failed > 0 {
+		os.Exit(exitError)
+	}
+}
+
+// handleClean handles the clean command
+func handleClean() {
+	cleanCommand := flag.NewFlagSet(cmdClean, flag.ExitOnError)
+	deleteBranches := cleanCommand.Bool("d", false, "Delete branches as well")
+	var yes bool
+	cleanCommand.BoolVar(&yes, "y", false, "Don't ask for confirmation")
+	cleanCommand.BoolVar(&yes, "yes", false, "Don't ask for confirmation")
 
 	// Parse remaining args
-	err := deleteCommand.Parse(os.Args[2:])
+	parseFlags(cleanCommand, os.Args[2:])
+
+	wt := newRepoManager()
+	repo, err := wt.RepoName()
+	if err != nil {
+		fail(err)
+	}
+	tickets, err := wt.Tickets()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", util.ColorRed, err, util.ColorReset)
-		os.Exit(1)
+		fail(err)
+	}
+	if len(tickets) == 0 {
+		util.Infof("No worktrees found for repository %s\n", util.Colorize(repo, util.ColorYellow))
+		return
 	}
 
-	args := deleteCommand.Args()
-	if len(args) < 1 {
-		fmt.Fprintf(os.Stderr, "%sError: Ticket ID required%s\n", util.ColorRed, util.ColorReset)
-		os.Exit(1)
+	if !yes {
+		if !util.IsTerminal(os.Stdin) {
+			usagef("Refusing to delete all worktrees without confirmation, pass --yes")
+		}
+		prompt := fmt.Sprintf("Delete all %d worktrees for repository %s?", len(tickets), repo)
+		if !util.Confirm(os.Stdin, os.Stderr, prompt) {
+			fatalf("Aborted")
+		}
 	}
 
-	ticket := args[0]
-	wt := worktree.NewManager()
-	if err := wt.Delete(ticket, *deleteBranch); err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", util.ColorRed, err, util.ColorReset)
-		os.Exit(1)
+	results, err := wt.DeleteAll(*deleteBranches)
+	if err != nil {
+		fail(err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "  %s %s: %v\n", util.Colorize("failed", util.ColorRed), result.Ticket, result.Err)
+			continue
+		}
+		util.Infof("  %s %s\n", util.Colorize("removed", util.ColorGreen), result.Ticket)
+	}
+	util.Infof("Removed %d of %d worktrees\n", len(results)-failed, len(results))
+	if failed > 0 {
+		os.Exit(exitError)
 	}
 }
 
 // handleList handles the list command
 func handleList() {
-	wt := worktree.NewManager()
-	if err := wt.List(); err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", util.ColorRed, err, util.ColorReset)
-		os.Exit(1)
+	listCommand := flag.NewFlagSet(cmdList, flag.ExitOnError)
+	jsonFlag := listCommand.Bool("json", false, "Output worktrees in a JSON envelope, like the global --json")
+	porcelain := listCommand.Bool("porcelain", false, "Output worktrees as key-value lines for scripts")
+	size := listCommand.Bool("size", false, "Show the disk usage of each worktree")
+	all := listCommand.Bool("all", false, "Include git worktrees outside the managed directory")
+	noHeader := listCommand.Bool("no-header", false, "Leave out the title and column headings")
+	global := listCommand.Bool("global", false, "List the worktrees of every repository under the base path")
+	sortBy := listCommand.String("sort", "", "Sort by name, branch, mtime (newest first), or size (largest first)")
+	reverse := listCommand.Bool("reverse", false, "Reverse the sort order")
+	stale := listCommand.Bool("stale", false, "Mark worktrees whose branch is fully merged into the default base branch")
+	var long bool
+	listCommand.BoolVar(&long, "l", false, "Show the base and creation time of each worktree")
+	listCommand.BoolVar(&long, "long", false, "Show the base and creation time of each worktree")
+
+	// Parse remaining args
+	parseFlags(listCommand, os.Args[2:])
+	if *global && *all {
+		usagef("--all cannot be combined with --global")
+	}
+	if *global && *stale {
+		usagef("--stale cannot be combined with --global")
+	}
+	// The command's own --json is the same as the global flag
+	jsonOutput = jsonOutput || *jsonFlag
+	if jsonOutput && *porcelain {
+		usagef("--json cannot be combined with --porcelain")
+	}
+	if *sortBy != "" && !slices.Contains(worktree.SortKeys, *sortBy) {
+		usagef("Invalid --sort %q, expected one of: %s", *sortBy, strings.Join(worktree.SortKeys, ", "))
+	}
+	if *reverse && *sortBy == "" {
+		usagef("--reverse requires --sort")
+	}
+	listOpts := worktree.ListOptions{Size: *size, All: *all, Sort: *sortBy, Reverse: *reverse, Stale: *stale}
+
+	var wt *worktree.Manager
+	var entries []worktree.Entry
+	var err error
+	if *global {
+		// The base path is read directly, so no repository is needed
+		wt = worktree.NewManager()
+		entries, err = wt.ListGlobal(listOpts)
+	} else {
+		wt = newRepoManager()
+		entries, err = wt.List(listOpts)
+	}
+	// Worktrees that couldn't be read are shown with their error, and the
+	// command fails once the listing is out
+	var listErr *worktree.ListError
+	if err != nil && !errors.As(err, &listErr) {
+		fail(err)
+	}
+
+	renderOpts := worktree.RenderListOptions{Size: *size, NoHeader: *noHeader, Details: long}
+	switch {
+	case jsonOutput:
+		if entries == nil {
+			entries = []worktree.Entry{}
+		}
+		writeJSON(entries, err)
+	case *porcelain:
+		worktree.RenderPorcelain(os.Stdout, entries)
+	case *global:
+		worktree.RenderGlobalList(os.Stdout, wt.BasePath(), entries, renderOpts)
+	default:
+		repo, err := wt.RepoName()
+		if err != nil {
+			fail(err)
+		}
+		worktree.RenderList(os.Stdout, repo, wt.BasePath(), entries, renderOpts)
+	}
+	if listErr != nil {
+		fail(listErr)
+	}
+}
+
+// handlePrune handles the prune command
+func handlePrune() {
+	pruneCommand := flag.NewFlagSet(cmdPrune, flag.ExitOnError)
+	dryRun := pruneCommand.Bool("dry-run", false, "Show what would be removed without deleting")
+
+	// Parse remaining args
+	parseFlags(pruneCommand, os.Args[2:])
+
+	wt := newRepoManager()
+	stale, err := wt.Prune(*dryRun)
+	if err != nil {
+		fail(err)
+	}
+
+	// The dry-run listing is the command's result, so it is never silenced
+	out := util.InfoWriter()
+	if *dryRun {
+		out = os.Stdout
+	}
+	worktree.RenderPrune(out, stale, *dryRun)
+}
+
+// handleStatus handles the status command
+func handleStatus() {
+	wt := newRepoManager()
+	repo, err := wt.RepoName()
+	if err != nil {
+		fail(err)
+	}
+
+	statuses, err := wt.Statuses()
+	if err != nil {
+		fail(err)
+	}
+	if jsonOutput {
+		if statuses == nil {
+			statuses = []worktree.StatusEntry{}
+		}
+		writeJSON(statuses, nil)
+		return
+	}
+	worktree.RenderStatus(os.Stdout, repo, statuses)
+}
+
+// handleRecent handles the recent command
+func handleRecent() {
+	recentCommand := flag.NewFlagSet(cmdRecent, flag.ExitOnError)
+	limit := recentCommand.Int("limit", 0, "Show at most N worktrees (default: all)")
+
+	// Parse remaining args
+	parseFlags(recentCommand, os.Args[2:])
+
+	wt := newRepoManager()
+	repo, err := wt.RepoName()
+	if err != nil {
+		fail(err)
+	}
+
+	entries, err := wt.Recent(*limit)
+	if err != nil {
+		fail(err)
+	}
+	worktree.RenderRecent(os.Stdout, repo, entries)
+}
+
+// handleOpen handles the open command
+func handleOpen() {
+	openCommand := flag.NewFlagSet(cmdOpen, flag.ExitOnError)
+	editor := openCommand.String("editor", "", "Editor command to use instead of the configured one")
+
+	// Parse remaining args, allowing flags after the ticket
+	args := parseFlags(openCommand, os.Args[2:])
+	if len(args) < 1 {
+		usagef("Ticket ID required")
+	}
+
+	wt := newRepoManager()
+	if err := wt.Open(args[0], *editor); err != nil {
+		fail(err)
+	}
+}
+
+// handleRename handles the rename command
+func handleRename() {
+	if len(os.Args) < 4 {
+		usagef("Old and new ticket IDs required")
+	}
+
+	wt := newRepoManager()
+	if err := wt.Rename(os.Args[2], os.Args[3]); err != nil {
+		fail(err)
+	}
+}
+
+// selectTicket asks the user to pick one of the repository's worktrees.
+// The menu goes to stderr so stdout stays clean for eval.
+func selectTicket(wt *worktree.Manager) string {
+	tickets, err := wt.Tickets()
+	if err != nil {
+		fail(err)
+	}
+	if len(tickets) == 0 {
+		fatalf("No worktrees found for this repository")
+	}
+
+	index, err := util.Select(os.Stdin, os.Stderr, "Select a worktree", tickets)
+	if err != nil {
+		fail(err)
+	}
+	return tickets[index]
+}
+
+// cdShells lists the shells `cd --shell` prints a command for
+var cdShells = []string{"sh", "bash", "zsh", "fish", "powershell", "pwsh", "cmd"}
+
+// defaultCDShell picks the shell syntax cd prints when --shell is not
+// given: PowerShell on Windows, unless a POSIX shell such as Git Bash set
+// $SHELL, and sh everywhere else
+func defaultCDShell(goos string) string {
+	if goos == "windows" && os.Getenv("SHELL") == "" {
+		return "powershell"
+	}
+	return "sh"
+}
+
+// shellCD returns the command that changes to path in shell, quoted so
+// that it works for paths with spaces
+func shellCD(shell, path string) string {
+	switch shell {
+	case "powershell", "pwsh":
+		return "Set-Location -LiteralPath '" + strings.ReplaceAll(path, "'", "''") + "'"
+	case "cmd":
+		// Windows paths can't contain double quotes, so none need escaping
+		return `cd /d "` + path + `"`
+	default:
+		return "cd " + util.ShellQuote(path)
+	}
+}
+
+// cdHint explains how to run the printed cd command in shell
+func cdHint(shell, ticket string) string {
+	switch shell {
+	case "powershell", "pwsh":
+		return fmt.Sprintf("Note: Run with Invoke-Expression (go-worktree cd %s --shell %s) or use gwt from shellinit --shell powershell", ticket, shell)
+	case "cmd":
+		return "Note: cmd can't change directory from a program, run the printed command yourself"
+	default:
+		return fmt.Sprintf("Note: Run with eval \"$(go-worktree cd %s)\" or use gwt from shellinit to change directory", ticket)
 	}
 }
 
 // handleCD handles the cd command
 func handleCD() {
-	if len(os.Args) < 3 {
-		fmt.Fprintf(os.Stderr, "%sError: Ticket ID required%s\n", util.ColorRed, util.ColorReset)
-		os.Exit(1)
+	cdCommand := flag.NewFlagSet(cmdCD, flag.ExitOnError)
+	pathOnly := cdCommand.Bool("path-only", false, "Print only the worktree path, without the cd prefix")
+	shell := cdCommand.String("shell", defaultCDShell(runtime.GOOS), "Shell syntax to print: sh, bash, zsh, fish, powershell, or cmd")
+
+	// Parse remaining args, allowing flags after the ticket
+	args := parseFlags(cdCommand, os.Args[2:])
+	if !slices.Contains(cdShells, *shell) {
+		usagef("Unsupported shell: %s (expected sh, bash, zsh, fish, powershell, or cmd)", *shell)
 	}
 
-	ticket := os.Args[2]
-	wt := worktree.NewManager()
-	path, err := wt.GetPath(ticket)
+	wt := newRepoManager()
+
+	var ticket string
+	if len(args) >= 1 {
+		ticket = args[0]
+	} else if util.IsTerminal(os.Stdin) && !jsonOutput {
+		ticket = selectTicket(wt)
+	} else {
+		usagef("Ticket ID required")
+	}
+
+	path, err := wt.ExistingPath(ticket)
+	if jsonOutput && errors.Is(err, worktree.ErrWorktreeNotFound) {
+		printJSON(cdResult{Ticket: ticket}, errJSONNotFound)
+		os.Exit(exitNotFound)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", util.ColorRed, err, util.ColorReset)
-		os.Exit(1)
+		fail(err)
+	}
+
+	if jsonOutput {
+		resolved, err := wt.ResolveTicket(ticket)
+		if err != nil {
+			fail(err)
+		}
+		writeJSON(cdResult{Ticket: resolved, Path: path}, nil)
+		return
+	}
+	if *pathOnly {
+		fmt.Println(path)
+		return
 	}
 
 	// Output command for shell to evaluate
-	fmt.Printf("cd %s\n", path)
-	fmt.Fprintf(os.Stderr, "%sNote: Run with eval $(go-worktree cd %s) to change directory%s\n",
-		util.ColorYellow, ticket, util.ColorReset)
+	fmt.Println(shellCD(*shell, path))
+
+	// Only remind about eval when the output isn't already being captured
+	if util.IsTerminal(os.Stdout) && !util.Quiet() {
+		fmt.Fprintln(os.Stderr, util.Colorize(cdHint(*shell, ticket), util.ColorYellow))
+	}
 }
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/mdelgado509/go-worktree/internal/git"
+	"github.com/mdelgado509/go-worktree/pkg/worktree"
+)
+
+// TestExitCode tests mapping error kinds to process exit codes
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected int
+	}{
+		{errors.New("boom"), exitError},
+		{fmt.Errorf("listing: %w", worktree.ErrNotARepo), exitNotInRepo},
+		{fmt.Errorf("cd: %w", worktree.ErrWorktreeNotFound), exitNotFound},
+		{worktree.ErrBranchNotFound, exitNotFound},
+		{worktree.ErrWorktreeExists, exitExists},
+		{worktree.ErrBranchCheckedOut, exitExists},
+		{worktree.ErrGitFailed, exitGitFailed},
+		{worktree.ErrWorktreeDirty, exitDirty},
+		// Failures git reports match the same kinds as the manager's own
+		{&git.KnownError{Kind: git.ErrBranchNotFound, Err: &git.CommandError{Err: errors.New("exit status 1")}}, exitNotFound},
+		{&git.KnownError{Kind: git.ErrWorktreeDirty, Err: &git.CommandError{Err: errors.New("exit status 128")}}, exitDirty},
+		{fmt.Errorf("fetch: %w", context.Canceled), exitInterrupted},
+	}
+
+	for _, tc := range testCases {
+		if code := exitCode(tc.err); code != tc.expected {
+			t.Errorf("exitCode(%v): expected %d, got %d", tc.err, tc.expected, code)
+		}
+	}
+}
+
+// buildTestBinary builds the binary into tmp and creates a repository with
+// one commit next to it, returning both paths
+func buildTestBinary(t *testing.T, tmp string) (binary, repo string) {
+	t.Helper()
+	binary = filepath.Join(tmp, "go-worktree")
+	if output, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build binary: %v\n%s", err, output)
+	}
+
+	repo = filepath.Join(tmp, "repo")
+	if err := os.Mkdir(repo, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	return binary, repo
+}
+
+// TestBinaryExitCodes tests the exit codes of the built binary
+func TestBinaryExitCodes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test: builds the binary")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Skipping test: git not installed")
+	}
+
+	tmp := t.TempDir()
+	binary, repo := buildTestBinary(t, tmp)
+	outside := filepath.Join(tmp, "outside")
+	if err := os.Mkdir(outside, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	env := append(os.Environ(),
+		"HOME="+filepath.Join(tmp, "home"),
+		"XDG_CONFIG_HOME=",
+		"XDG_STATE_HOME=",
+		"GO_WORKTREE_HOME="+filepath.Join(tmp, "worktrees"),
+		"GIT_CEILING_DIRECTORIES="+tmp,
+		"NO_COLOR=1")
+
+	testCases := []struct {
+		dir      string
+		args     []string
+		expected int
+	}{
+		{repo, []string{"list"}, 0},
+		{repo, []string{"create", "ABC-1", "--from-current"}, 0},
+		{repo, []string{"delete"}, exitUsage},
+		{repo, []string{"bogus"}, exitUsage},
+		{outside, []string{"list"}, exitNotInRepo},
+		{repo, []string{"cd", "XYZ-9"}, exitNotFound},
+		{repo, []string{"create", "ABC-1", "--from-current"}, exitExists},
+		{repo, []string{"create", "ABC-2", "--cd", "--json"}, exitUsage},
+		{repo, []string{"create", "ABC-2", "--depth", "-1"}, exitUsage},
+	}
+
+	for _, tc := range testCases {
+		cmd := exec.Command(binary, tc.args...)
+		cmd.Dir = tc.dir
+		cmd.Env = env
+		output, err := cmd.CombinedOutput()
+
+		code := 0
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		} else if err != nil {
+			t.Fatalf("Failed to run %v: %v", tc.args, err)
+		}
+		if code != tc.expected {
+			t.Errorf("%v: expected exit code %d, got %d\n%s", tc.args, tc.expected, code, output)
+		}
+	}
+}
+
+// TestBinaryCreateCD tests that create --cd prints only the cd command on
+// stdout
+func TestBinaryCreateCD(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test: builds the binary")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Skipping test: git not installed")
+	}
+
+	tmp := t.TempDir()
+	binary, repo := buildTestBinary(t, tmp)
+
+	var stderr strings.Builder
+	cmd := exec.Command(binary, "create", "ABC-1", "--from-current", "--cd")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"HOME="+filepath.Join(tmp, "home"),
+		"XDG_CONFIG_HOME=",
+		"XDG_STATE_HOME=",
+		"GO_WORKTREE_HOME="+filepath.Join(tmp, "worktrees"),
+		"NO_COLOR=1")
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("create --cd failed: %v\n%s", err, stderr.String())
+	}
+
+	expected := shellCD(defaultCDShell(runtime.GOOS), filepath.Join(tmp, "worktrees", "repo", "ABC-1")) + "\n"
+	if string(output) != expected {
+		t.Errorf("Expected stdout %q, got %q", expected, output)
+	}
+	if !strings.Contains(stderr.String(), "Success!") {
+		t.Errorf("Expected progress messages on stderr, got %q", stderr.String())
+	}
+}
+
+// TestBinaryStreams tests that progress messages go to stderr and only
+// data goes to stdout
+func TestBinaryStreams(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test: builds the binary")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Skipping test: git not installed")
+	}
+
+	tmp := t.TempDir()
+	binary, repo := buildTestBinary(t, tmp)
+	env := append(os.Environ(),
+		"HOME="+filepath.Join(tmp, "home"),
+		"XDG_CONFIG_HOME=",
+		"XDG_STATE_HOME=",
+		"GO_WORKTREE_HOME="+filepath.Join(tmp, "worktrees"),
+		"NO_COLOR=1")
+	path := filepath.Join(tmp, "worktrees", "repo", "ABC-1")
+
+	testCases := []struct {
+		args   []string
+		stdout string
+		stderr string
+	}{
+		{[]string{"create", "ABC-1", "ABC-2", "ABC-3", "--from-current"}, "", "Created 3 of 3 worktrees"},
+		{[]string{"cd", "ABC-1", "--path-only"}, path + "\n", ""},
+		{[]string{"list", "--no-header"}, path, ""},
+		{[]string{"delete", "ABC-1", "ABC-2", "ABC-3"}, "", "Removed 3 of 3 worktrees"},
+		{[]string{"delete", "ABC-1"}, "", "worktree for ticket ABC-1 not found"},
+	}
+
+	for _, tc := range testCases {
+		var stdout, stderr strings.Builder
+		cmd := exec.Command(binary, tc.args...)
+		cmd.Dir = repo
+		cmd.Env = env
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		cmd.Run()
+
+		if tc.stdout == "" && stdout.Len() != 0 || !strings.Contains(stdout.String(), tc.stdout) {
+			t.Errorf("%v: expected stdout %q, got %q", tc.args, tc.stdout, stdout.String())
+		}
+		if tc.stderr == "" && stderr.Len() != 0 || !strings.Contains(stderr.String(), tc.stderr) {
+			t.Errorf("%v: expected stderr %q, got %q", tc.args, tc.stderr, stderr.String())
+		}
+	}
+}
+
+// TestBinaryJSON tests that the global --json flag wraps results and
+// errors in the same envelope
+func TestBinaryJSON(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test: builds the binary")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Skipping test: git not installed")
+	}
+
+	tmp := t.TempDir()
+	binary, repo := buildTestBinary(t, tmp)
+	env := append(os.Environ(),
+		"HOME="+filepath.Join(tmp, "home"),
+		"XDG_CONFIG_HOME=",
+		"XDG_STATE_HOME=",
+		"GO_WORKTREE_HOME="+filepath.Join(tmp, "worktrees"),
+		"NO_COLOR=1")
+	path := filepath.Join(tmp, "worktrees", "repo", "ABC-1")
+
+	testCases := []struct {
+		args     []string
+		expected jsonEnvelope
+		code     int
+	}{
+		{
+			[]string{"--json", "create", "ABC-1", "--from-current"},
+			jsonEnvelope{Command: "create", Result: map[string]any{"ticket": "ABC-1", "path": path, "branch": "ABC-1", "base": "main"}},
+			0,
+		},
+		{
+			// The command's own --json prints the same envelope, failures
+			// included
+			[]string{"create", "ABC-1", "--from-current", "--json"},
+			jsonEnvelope{Command: "create", Result: map[string]any{"ticket": "ABC-1", "path": path, "branch": "ABC-1"}, Error: ptr("directory already exists: " + path)},
+			exitExists,
+		},
+		{
+			[]string{"--json", "cd", "ABC"},
+			jsonEnvelope{Command: "cd", Result: map[string]any{"ticket": "ABC-1", "path": path}},
+			0,
+		},
+		{
+			[]string{"--json", "cd", "XYZ-9"},
+			jsonEnvelope{Command: "cd", Result: map[string]any{"ticket": "XYZ-9"}, Error: ptr("not_found")},
+			exitNotFound,
+		},
+		{
+			[]string{"--json", "prune"},
+			jsonEnvelope{Command: "prune", Error: ptr("--json only applies to create, delete, list, cd, status, version")},
+			exitUsage,
+		},
+		{
+			[]string{"--json", "delete", "ABC-1"},
+			jsonEnvelope{Command: "delete", Result: map[string]any{"ticket": "ABC-1", "path": path}},
+			0,
+		},
+		{
+			[]string{"--json", "ls"},
+			jsonEnvelope{Command: "list", Result: []any{}},
+			0,
+		},
+		{
+			[]string{"list", "--json"},
+			jsonEnvelope{Command: "list", Result: []any{}},
+			0,
+		},
+	}
+
+	for _, tc := range testCases {
+		cmd := exec.Command(binary, tc.args...)
+		cmd.Dir = repo
+		cmd.Env = env
+		output, err := cmd.Output()
+
+		code := 0
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		} else if err != nil {
+			t.Fatalf("Failed to run %v: %v", tc.args, err)
+		}
+		if code != tc.code {
+			t.Errorf("%v: expected exit code %d, got %d", tc.args, tc.code, code)
+		}
+
+		var envelope jsonEnvelope
+		if err := json.Unmarshal(output, &envelope); err != nil {
+			t.Fatalf("%v: expected a JSON envelope, got %q: %v", tc.args, output, err)
+		}
+		if !reflect.DeepEqual(envelope, tc.expected) {
+			t.Errorf("%v: expected %+v, got %+v", tc.args, tc.expected, envelope)
+		}
+	}
+}
+
+// ptr returns a pointer to s, for the envelope's error
+func ptr(s string) *string { return &s }
+
+// TestShellCD tests that the cd command for a path with spaces and quotes
+// lands in that directory when evaluated by the shell
+func TestShellCD(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("Skipping test: sh not installed")
+	}
+
+	dir := filepath.Join(t.TempDir(), "My Repo", "it's ABC-746")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	command := shellCD("sh", dir)
+	if command != "cd '"+filepath.Dir(dir)+"/it'\\''s ABC-746'" {
+		t.Errorf("Unexpected command %s", command)
+	}
+
+	output, err := exec.Command("sh", "-c", `eval "$1" && pwd -P`, "sh", command).Output()
+	if err != nil {
+		t.Fatalf("Evaluating %s failed: %v", command, err)
+	}
+	expected, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatalf("Failed to resolve dir: %v", err)
+	}
+	if got := strings.TrimSpace(string(output)); got != expected {
+		t.Errorf("Expected to change to %s, got %s", expected, got)
+	}
+}
+
+// TestShellCDWindows tests the cd command syntax for PowerShell and cmd
+func TestShellCDWindows(t *testing.T) {
+	testCases := []struct {
+		shell    string
+		path     string
+		expected string
+	}{
+		{"powershell", `C:\Users\dev\worktrees\app\ABC-746`, `Set-Location -LiteralPath 'C:\Users\dev\worktrees\app\ABC-746'`},
+		{"pwsh", `C:\Users\O'Brien\worktrees\ABC-746`, `Set-Location -LiteralPath 'C:\Users\O''Brien\worktrees\ABC-746'`},
+		{"cmd", `C:\Users\dev\My Worktrees\ABC-746`, `cd /d "C:\Users\dev\My Worktrees\ABC-746"`},
+		{"fish", "/home/dev/My Worktrees/ABC-746", "cd '/home/dev/My Worktrees/ABC-746'"},
+	}
+
+	for _, tc := range testCases {
+		if result := shellCD(tc.shell, tc.path); result != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.shell, tc.expected, result)
+		}
+	}
+}
+
+// TestDefaultCDShell tests picking PowerShell syntax on Windows unless a
+// POSIX shell is running
+func TestDefaultCDShell(t *testing.T) {
+	t.Setenv("SHELL", "")
+	if shell := defaultCDShell("windows"); shell != "powershell" {
+		t.Errorf("Expected powershell on Windows, got %s", shell)
+	}
+	if shell := defaultCDShell("linux"); shell != "sh" {
+		t.Errorf("Expected sh on Linux, got %s", shell)
+	}
+
+	t.Setenv("SHELL", "/usr/bin/bash")
+	if shell := defaultCDShell("windows"); shell != "sh" {
+		t.Errorf("Expected sh in Git Bash on Windows, got %s", shell)
+	}
+}
+
+// TestVersionInfo tests that the build metadata set with -ldflags is
+// marshaled under its JSON names and shown in the human-readable version
+func TestVersionInfo(t *testing.T) {
+	defer func(c, d string) { commit, buildDate = c, d }(commit, buildDate)
+	commit, buildDate = "3f2a9c1d8e4b7a6f5e4d3c2b1a0f9e8d7c6b5a4f", "2024-03-07T12:00:00Z"
+
+	info := buildInfo()
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"version":    version,
+		"commit":     commit,
+		"build_date": buildDate,
+		"go_version": runtime.Version(),
+		"platform":   runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Expected %v, got %v", expected, decoded)
+	}
+
+	want := fmt.Sprintf("go-worktree version %s (commit 3f2a9c1d8e4b, built 2024-03-07T12:00:00Z, %s, %s/%s)",
+		version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if info.String() != want {
+		t.Errorf("Expected %q, got %q", want, info.String())
+	}
+
+	// Without -ldflags the commit and its time come from the build settings
+	info = versionInfo{Version: version, GoVersion: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH}
+	info.applyVCS([]debug.BuildSetting{
+		{Key: "vcs", Value: "git"},
+		{Key: "vcs.revision", Value: "3f2a9c1d8e4b7a6f5e4d3c2b1a0f9e8d7c6b5a4f"},
+		{Key: "vcs.time", Value: "2024-03-06T09:30:00Z"},
+	})
+	want = fmt.Sprintf("go-worktree version %s (commit 3f2a9c1d8e4b, committed 2024-03-06T09:30:00Z, %s, %s/%s)",
+		version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if info.String() != want {
+		t.Errorf("Expected %q, got %q", want, info.String())
+	}
+}
+
+// TestExpandAliases tests expanding user-defined aliases with templates,
+// appended arguments, aliases of aliases, and loops
+func TestExpandAliases(t *testing.T) {
+	aliases := map[string]string{
+		"review": "create {{.}} --track-remote",
+		"hotfix": "create {{index . 0}} --name hotfix-{{index . 0}} --base 'release/1.0'",
+		"rmd":    "delete -d",
+		"gone":   "rmd",
+		"create": "create --no-fetch",
+		"loop":   "again",
+		"again":  "loop",
+		"broken": "create 'ABC",
+	}
+
+	testCases := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"review", "ABC-1"}, []string{"create", "ABC-1", "--track-remote"}},
+		{[]string{"hotfix", "ABC-2"}, []string{"create", "ABC-2", "--name", "hotfix-ABC-2", "--base", "release/1.0"}},
+		{[]string{"gone", "ABC-3", "ABC-4"}, []string{"delete", "-d", "ABC-3", "ABC-4"}},
+		// Built-in commands win over aliases
+		{[]string{"create", "ABC-5"}, []string{"create", "ABC-5"}},
+		{[]string{"list"}, []string{"list"}},
+	}
+
+	for _, tc := range testCases {
+		result, err := expandAliases(aliases, tc.args)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, result)
+		}
+	}
+
+	for _, args := range [][]string{{"loop"}, {"broken"}, {"hotfix"}} {
+		if _, err := expandAliases(aliases, args); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+	if _, err := expandAliases(aliases, []string{"loop"}); err == nil || err.Error() != "alias loop: loop -> again -> loop" {
+		t.Errorf("Expected the loop to be named, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+const cmdPR = "pr"
+
+// handlePR opens the GitHub page for creating a pull request from the
+// current worktree's branch
+func handlePR() {
+	prCommand := flag.NewFlagSet(cmdPR, flag.ExitOnError)
+	base := prCommand.String("base", "", "Branch to open the pull request against (default: config, then the remote's default branch, then main)")
+
+	// Parse remaining args
+	if args := parseFlags(prCommand, os.Args[2:]); len(args) > 0 {
+		usagef("Unexpected argument %q, run pr from inside the worktree", args[0])
+	}
+
+	wt := newRepoManager()
+	url, err := wt.CompareURL(*base)
+	if err != nil {
+		fail(err)
+	}
+
+	util.Infof("Opening %s\n", util.Colorize(url, util.ColorBlue))
+	if err := util.OpenURL(url); err != nil {
+		fail(fmt.Errorf("%w, open %s in your browser instead", err, url))
+	}
+}
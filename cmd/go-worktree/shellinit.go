@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+const cmdShellInit = "shellinit"
+
+const posixShellInit = `# gwt wraps go-worktree so that "gwt cd TICKET" changes the directory of
+# the current shell. All other commands are passed to the real binary.
+gwt() {
+    if [ "$1" = "cd" ] || [ "$1" = "switch" ]; then
+        shift
+        local gwt_cmd
+        gwt_cmd="$(command go-worktree cd "$@")" || return $?
+        eval "$gwt_cmd"
+    else
+        command go-worktree "$@"
+    fi
+}
+`
+
+const fishShellInit = `# gwt wraps go-worktree so that "gwt cd TICKET" changes the directory of
+# the current shell. All other commands are passed to the real binary.
+function gwt --description 'go-worktree wrapper that can change directory'
+    if test "$argv[1]" = cd; or test "$argv[1]" = switch
+        set -l gwt_cmd (command go-worktree cd $argv[2..-1]); or return $status
+        eval $gwt_cmd
+    else
+        command go-worktree $argv
+    end
+end
+`
+
+const powershellShellInit = `# gwt wraps go-worktree so that "gwt cd TICKET" changes the directory of
+# the current shell. All other commands are passed to the real binary.
+function gwt {
+    if ($args.Count -gt 0 -and ($args[0] -eq 'cd' -or $args[0] -eq 'switch')) {
+        $gwtCmd = & go-worktree cd --shell powershell @($args | Select-Object -Skip 1)
+        if ($LASTEXITCODE -ne 0) { return }
+        Invoke-Expression $gwtCmd
+    } else {
+        & go-worktree @args
+    }
+}
+`
+
+// handleShellInit prints the gwt shell function for the requested shell
+func handleShellInit() {
+	shellInitCommand := flag.NewFlagSet(cmdShellInit, flag.ExitOnError)
+	shell := shellInitCommand.String("shell", "bash", "Shell syntax to emit: bash, zsh, fish, or powershell")
+
+	// Parse remaining args
+	parseFlags(shellInitCommand, os.Args[2:])
+
+	switch *shell {
+	case "bash", "zsh":
+		fmt.Print(posixShellInit)
+	case "fish":
+		fmt.Print(fishShellInit)
+	case "powershell", "pwsh":
+		fmt.Print(powershellShellInit)
+	default:
+		usagef("Unsupported shell: %s (expected bash, zsh, fish, or powershell)", *shell)
+	}
+}
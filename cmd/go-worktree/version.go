@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+const cmdVersion = "version"
+
+// Build metadata, set at build time with
+// -ldflags "-X main.version=... -X main.commit=... -X main.buildDate=..."
+var (
+	version = "1.0.0"
+	// commit is the git commit the binary was built from; empty falls back
+	// to what the go command recorded in the binary, if anything
+	commit = ""
+	// buildDate is when the binary was built, in RFC 3339 form
+	buildDate = ""
+)
+
+// versionInfo is the output of version --json
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	// CommitDate is when the commit was made, known when the commit comes
+	// from what the go command recorded rather than from -ldflags
+	CommitDate string `json:"commit_date,omitempty"`
+	GoVersion  string `json:"go_version"`
+	Platform   string `json:"platform"`
+}
+
+// buildInfo returns the version and build metadata of the running binary
+func buildInfo() versionInfo {
+	info := versionInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok && info.Commit == "" {
+		info.applyVCS(build.Settings)
+	}
+	return info
+}
+
+// applyVCS fills in the commit and its time from the build settings that go
+// build and go install record for a checkout
+func (v *versionInfo) applyVCS(settings []debug.BuildSetting) {
+	for _, setting := range settings {
+		switch setting.Key {
+		case "vcs.revision":
+			v.Commit = setting.Value
+		case "vcs.time":
+			v.CommitDate = setting.Value
+		}
+	}
+}
+
+// String formats the version information for people
+func (v versionInfo) String() string {
+	details := []string{}
+	if v.Commit != "" {
+		details = append(details, "commit "+shortCommit(v.Commit))
+	}
+	if v.BuildDate != "" {
+		details = append(details, "built "+v.BuildDate)
+	}
+	if v.CommitDate != "" {
+		details = append(details, "committed "+v.CommitDate)
+	}
+	details = append(details, v.GoVersion, v.Platform)
+	return fmt.Sprintf("go-worktree version %s (%s)", v.Version, strings.Join(details, ", "))
+}
+
+// shortCommit abbreviates a full commit hash to its first 12 characters,
+// enough to stay unique in all but the largest repositories
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// handleVersion prints the version and build metadata
+func handleVersion() {
+	versionCommand := flag.NewFlagSet(cmdVersion, flag.ExitOnError)
+	jsonFlag := versionCommand.Bool("json", false, "Output the version and build metadata in a JSON envelope, like the global --json")
+	parseFlags(versionCommand, os.Args[2:])
+
+	info := buildInfo()
+	if jsonOutput || *jsonFlag {
+		writeJSON(info, nil)
+		return
+	}
+	fmt.Println(info)
+}
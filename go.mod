@@ -1,3 +1,9 @@
 module github.com/mdelgado509/go-worktree
 
 go 1.22.5
+
+require (
+	golang.org/x/sys v0.20.0
+	golang.org/x/term v0.20.0
+	gopkg.in/yaml.v3 v3.0.1
+)
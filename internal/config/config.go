@@ -0,0 +1,204 @@
+// Package config loads user settings for go-worktree
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultBaseBranch is the base branch used when neither a flag nor the
+// config file specifies one and the remote's default branch is unknown
+const DefaultBaseBranch = "main"
+
+// DefaultDirTemplate names each worktree directory after its ticket
+const DefaultDirTemplate = "{{.Ticket}}"
+
+// DefaultRemote is the remote fetched from when none is configured
+const DefaultRemote = "origin"
+
+// DefaultProtectedBranches are the branches `delete -d` refuses to delete
+// when protected_branches is not configured
+var DefaultProtectedBranches = []string{"main", "master", "develop"}
+
+// Repository key strategies used to namespace worktrees under the base path
+const (
+	RepoKeyBasename = "basename"
+	RepoKeyRemote   = "remote"
+)
+
+// Remotes the namespace is read from with the remote repository key
+const (
+	NamespaceFromOrigin   = "origin"
+	NamespaceFromUpstream = "upstream"
+)
+
+// Config holds the settings read from the config file
+type Config struct {
+	BasePath          string `yaml:"base_path"`
+	DefaultBaseBranch string `yaml:"default_base_branch"`
+	BranchPrefix      string `yaml:"branch_prefix"`
+	// CopyOnCreate lists glob patterns, relative to the repository root,
+	// of files copied into each new worktree (e.g. ".env"). Only files git
+	// ignores are copied unless create is given --copy-all.
+	CopyOnCreate []string `yaml:"copy_on_create"`
+	// TemplateDir is a directory whose contents are copied into each new
+	// worktree, leaving files that already exist there alone
+	TemplateDir string `yaml:"template_dir"`
+	// PostCreateHook is a shell command run inside each new worktree
+	PostCreateHook string `yaml:"post_create_hook"`
+	// Editor is the command used by `open`, taking precedence over $EDITOR
+	Editor string `yaml:"editor"`
+	// TicketPattern is an optional regular expression new tickets must match
+	TicketPattern string `yaml:"ticket_pattern"`
+	// RepoKey selects how the repository namespace is derived: "basename"
+	// (default) uses the checkout's directory name, "remote" uses the
+	// org/repo of the origin remote
+	RepoKey string `yaml:"repo_key"`
+	// NamespaceFrom picks the remote whose org/repo is the namespace with
+	// repo_key remote: "origin" (default), or "upstream", falling back to
+	// origin, so forks share their upstream's namespace. "upstream" implies
+	// repo_key remote when repo_key is unset.
+	NamespaceFrom string `yaml:"namespace_from"`
+	// LowercaseRepoName lowercases the repository namespace directory
+	LowercaseRepoName bool `yaml:"lowercase_repo_name"`
+	// Remote is the remote the base branch is fetched from
+	Remote string `yaml:"remote"`
+	// ProtectedBranches are never deleted by `delete -d` without
+	// --force-protected; unset uses DefaultProtectedBranches
+	ProtectedBranches []string `yaml:"protected_branches"`
+	// DirTemplate is a text/template for worktree directory names, using
+	// {{.Ticket}}, {{.Branch}}, and {{.Date}}; unset uses
+	// DefaultDirTemplate
+	DirTemplate string `yaml:"dir_template"`
+	// FetchTimeout limits how long fetching the base branch may take,
+	// e.g. "1m"; zero uses git.DefaultFetchTimeout
+	FetchTimeout time.Duration `yaml:"fetch_timeout"`
+	// GitPath is the git executable to run, a path or a name on PATH;
+	// $GIT_BINARY takes precedence
+	GitPath string `yaml:"git_path"`
+	// Aliases maps names of user-defined commands to the command lines
+	// they run, such as "create {{.}} --track-remote". Built-in commands
+	// and their aliases take precedence.
+	Aliases map[string]string `yaml:"aliases"`
+}
+
+// DefaultPath returns the location of the user's config file, config.yaml
+// in ConfigDir
+func DefaultPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// Load reads the config file from the default location
+func Load() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return &Config{}, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads the config file at path. A missing file is not an error
+// and yields an empty config so built-in defaults apply.
+func LoadFile(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return &Config{}, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// RepoFileName is the optional per-repository settings file kept at the
+// root of the main worktree
+const RepoFileName = ".go-worktree"
+
+// RepoConfig holds the settings read from a repository's .go-worktree file
+type RepoConfig struct {
+	// BasePath overrides the global base path for this repository. A
+	// relative path is resolved against the repository root.
+	BasePath string `yaml:"base_path"`
+}
+
+// LoadRepoFile reads the .go-worktree file at the root of repoRoot. A
+// missing file is not an error and yields an empty config.
+func LoadRepoFile(repoRoot string) (*RepoConfig, error) {
+	cfg := &RepoConfig{}
+	path := filepath.Join(repoRoot, RepoFileName)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return &RepoConfig{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	// A leading ~ is left for the caller to expand like the global base_path
+	if cfg.BasePath != "" && !filepath.IsAbs(cfg.BasePath) && !strings.HasPrefix(cfg.BasePath, "~") {
+		cfg.BasePath = filepath.Join(repoRoot, cfg.BasePath)
+	}
+	return cfg, nil
+}
+
+// BaseBranch returns the base branch to use, preferring the explicit flag
+// value, then the config file, then the built-in default
+func (c *Config) BaseBranch(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if c.DefaultBaseBranch != "" {
+		return c.DefaultBaseBranch
+	}
+	return DefaultBaseBranch
+}
+
+// RemoteName returns the remote to fetch from, preferring the explicit flag
+// value, then the config file, then the built-in default
+func (c *Config) RemoteName(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if c.Remote != "" {
+		return c.Remote
+	}
+	return DefaultRemote
+}
+
+// IsProtected reports whether branch is in the configured protected
+// branches, or the defaults when none are configured. An explicit empty
+// list protects nothing.
+func (c *Config) IsProtected(branch string) bool {
+	protected := c.ProtectedBranches
+	if protected == nil {
+		protected = DefaultProtectedBranches
+	}
+	for _, name := range protected {
+		if name == branch {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeConfig writes contents to a temporary config file and returns its path
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+// TestLoadFile tests reading all supported fields
+func TestLoadFile(t *testing.T) {
+	path := writeConfig(t, `# team defaults
+base_path: /tmp/wt
+default_base_branch: develop
+branch_prefix: feature/
+copy_on_create:
+  - .env
+  - config/*.local.yaml
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.BasePath != "/tmp/wt" {
+		t.Errorf("Expected base_path /tmp/wt, got %q", cfg.BasePath)
+	}
+	if cfg.DefaultBaseBranch != "develop" {
+		t.Errorf("Expected default_base_branch develop, got %q", cfg.DefaultBaseBranch)
+	}
+	if cfg.BranchPrefix != "feature/" {
+		t.Errorf("Expected branch_prefix feature/, got %q", cfg.BranchPrefix)
+	}
+	if len(cfg.CopyOnCreate) != 2 || cfg.CopyOnCreate[1] != "config/*.local.yaml" {
+		t.Errorf("Expected two copy_on_create patterns, got %v", cfg.CopyOnCreate)
+	}
+}
+
+// TestLoadFileMissing tests that a missing file yields an empty config
+func TestLoadFileMissing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "nope.yaml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.BasePath != "" || cfg.DefaultBaseBranch != "" || cfg.BranchPrefix != "" || len(cfg.CopyOnCreate) != 0 {
+		t.Errorf("Expected empty config, got %+v", cfg)
+	}
+}
+
+// TestLoadFileInvalid tests that malformed YAML is reported
+func TestLoadFileInvalid(t *testing.T) {
+	path := writeConfig(t, "base_path: [unterminated\n")
+	if _, err := LoadFile(path); err == nil {
+		t.Errorf("Expected error for malformed config")
+	}
+}
+
+// TestBaseBranchPrecedence tests flag > config > default ordering
+func TestBaseBranchPrecedence(t *testing.T) {
+	path := writeConfig(t, "default_base_branch: develop\n")
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		cfg      *Config
+		flag     string
+		expected string
+	}{
+		{cfg, "release", "release"},
+		{cfg, "", "develop"},
+		{&Config{}, "", DefaultBaseBranch},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.cfg.BaseBranch(tc.flag); got != tc.expected {
+			t.Errorf("BaseBranch(%q) expected %q, got %q", tc.flag, tc.expected, got)
+		}
+	}
+}
+
+// TestRemoteNamePrecedence tests flag > config > default ordering
+func TestRemoteNamePrecedence(t *testing.T) {
+	cfg := &Config{Remote: "upstream"}
+	if got := cfg.RemoteName("fork"); got != "fork" {
+		t.Errorf("Expected fork, got %q", got)
+	}
+	if got := cfg.RemoteName(""); got != "upstream" {
+		t.Errorf("Expected upstream, got %q", got)
+	}
+	if got := (&Config{}).RemoteName(""); got != DefaultRemote {
+		t.Errorf("Expected %q, got %q", DefaultRemote, got)
+	}
+}
+
+// TestIsProtected tests the default and configured protected branches
+func TestIsProtected(t *testing.T) {
+	configured, err := LoadFile(writeConfig(t, "protected_branches: [release, main]\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	none, err := LoadFile(writeConfig(t, "protected_branches: []\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		cfg      *Config
+		branch   string
+		expected bool
+	}{
+		{&Config{}, "main", true},
+		{&Config{}, "master", true},
+		{&Config{}, "develop", true},
+		{&Config{}, "ABC-746", false},
+		{configured, "release", true},
+		{configured, "develop", false},
+		{none, "main", false},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.cfg.IsProtected(tc.branch); got != tc.expected {
+			t.Errorf("IsProtected(%q) with %v expected %v, got %v", tc.branch, tc.cfg.ProtectedBranches, tc.expected, got)
+		}
+	}
+}
+
+// TestLoadFileFetchTimeout tests parsing a duration for fetch_timeout
+func TestLoadFileFetchTimeout(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "fetch_timeout: 1m30s\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.FetchTimeout != 90*time.Second {
+		t.Errorf("Expected 1m30s, got %v", cfg.FetchTimeout)
+	}
+
+	if _, err := LoadFile(writeConfig(t, "fetch_timeout: soon\n")); err == nil {
+		t.Errorf("Expected error for invalid duration")
+	}
+}
+
+// TestLoadRepoFile tests absolute and repo-relative base_path overrides
+func TestLoadRepoFile(t *testing.T) {
+	repoRoot := t.TempDir()
+	testCases := []struct {
+		contents string
+		expected string
+	}{
+		{"base_path: /tmp/wt\n", "/tmp/wt"},
+		{"base_path: ../worktrees\n", filepath.Join(filepath.Dir(repoRoot), "worktrees")},
+		{"base_path: .worktrees\n", filepath.Join(repoRoot, ".worktrees")},
+		{"base_path: ~/wt\n", "~/wt"},
+		{"# nothing set\n", ""},
+	}
+
+	for _, tc := range testCases {
+		if err := os.WriteFile(filepath.Join(repoRoot, RepoFileName), []byte(tc.contents), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", RepoFileName, err)
+		}
+		cfg, err := LoadRepoFile(repoRoot)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.BasePath != tc.expected {
+			t.Errorf("For %q expected %q, got %q", tc.contents, tc.expected, cfg.BasePath)
+		}
+	}
+}
+
+// TestLoadRepoFileMissing tests that a repository without the file has no
+// override
+func TestLoadRepoFileMissing(t *testing.T) {
+	cfg, err := LoadRepoFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.BasePath != "" {
+		t.Errorf("Expected no base path, got %q", cfg.BasePath)
+	}
+}
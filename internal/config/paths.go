@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// appName is the directory go-worktree keeps its files in under the XDG
+// base directories
+const appName = "go-worktree"
+
+// Environment variables of the XDG base directory specification
+const (
+	ConfigHomeEnv = "XDG_CONFIG_HOME"
+	StateHomeEnv  = "XDG_STATE_HOME"
+)
+
+// ConfigDir returns the directory holding the config file:
+// $XDG_CONFIG_HOME/go-worktree, or ~/.config/go-worktree when the variable
+// is unset
+func ConfigDir() (string, error) {
+	return xdgDir(ConfigHomeEnv, ".config")
+}
+
+// StateDir returns the directory for what go-worktree keeps between runs,
+// such as the worktree index: $XDG_STATE_HOME/go-worktree, or
+// ~/.local/state/go-worktree when the variable is unset
+func StateDir() (string, error) {
+	return xdgDir(StateHomeEnv, filepath.Join(".local", "state"))
+}
+
+// IndexPath returns the index file for the worktrees kept in repoDir, a
+// repository's directory under the base path. The absolute path of
+// repoDir is mirrored under StateDir, so repositories under different base
+// paths never share an index.
+func IndexPath(repoDir string) (string, error) {
+	stateDir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(repoDir)
+	if err != nil {
+		return "", err
+	}
+	rel := strings.TrimLeft(strings.TrimPrefix(abs, filepath.VolumeName(abs)), `/\`)
+	return filepath.Join(stateDir, "index", rel+".json"), nil
+}
+
+// xdgDir returns the go-worktree directory under the base directory named
+// by env, or under fallback in the home directory. The specification says
+// to ignore a relative path in the variable, as if it were unset.
+func xdgDir(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find home directory: %w", err)
+	}
+	return filepath.Join(home, fallback, appName), nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestXDGDirs tests that the config and state directories follow the XDG
+// variables when set to absolute paths, and fall back to the home
+// directory otherwise
+func TestXDGDirs(t *testing.T) {
+	home := t.TempDir()
+	xdg := t.TempDir()
+	t.Setenv("HOME", home)
+
+	testCases := []struct {
+		env      string
+		dir      func() (string, error)
+		value    string
+		expected string
+	}{
+		{ConfigHomeEnv, ConfigDir, xdg, filepath.Join(xdg, "go-worktree")},
+		{ConfigHomeEnv, ConfigDir, "", filepath.Join(home, ".config", "go-worktree")},
+		{ConfigHomeEnv, ConfigDir, "relative/config", filepath.Join(home, ".config", "go-worktree")},
+		{StateHomeEnv, StateDir, xdg, filepath.Join(xdg, "go-worktree")},
+		{StateHomeEnv, StateDir, "", filepath.Join(home, ".local", "state", "go-worktree")},
+		{StateHomeEnv, StateDir, "relative/state", filepath.Join(home, ".local", "state", "go-worktree")},
+	}
+
+	for _, tc := range testCases {
+		t.Setenv(tc.env, tc.value)
+		got, err := tc.dir()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != tc.expected {
+			t.Errorf("%s=%q: expected %q, got %q", tc.env, tc.value, tc.expected, got)
+		}
+	}
+}
+
+// TestDefaultPathXDG tests that the config file moves with XDG_CONFIG_HOME
+func TestDefaultPathXDG(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv(ConfigHomeEnv, xdg)
+
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := filepath.Join(xdg, "go-worktree", "config.yaml"); path != expected {
+		t.Errorf("Expected %q, got %q", expected, path)
+	}
+}
+
+// TestIndexPath tests that each repository directory gets its own index
+// under the state directory
+func TestIndexPath(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv(StateHomeEnv, xdg)
+
+	repoDir := filepath.Join(t.TempDir(), "worktrees", "acme", "app")
+	path, err := IndexPath(repoDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rel, err := filepath.Rel(filepath.Join(xdg, "go-worktree", "index"), path)
+	if err != nil || filepath.IsAbs(rel) || rel[0] == '.' {
+		t.Fatalf("Expected %q to be under the state directory", path)
+	}
+	if filepath.Base(path) != "app.json" {
+		t.Errorf("Expected the index to be named after the repository, got %q", path)
+	}
+
+	other, _ := IndexPath(filepath.Join(t.TempDir(), "worktrees", "acme", "app"))
+	if other == path {
+		t.Errorf("Expected repositories under different base paths to get different indexes, both got %q", path)
+	}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Setting describes a config key managed by `config get` and `config set`
+type Setting struct {
+	Key string
+	// Default describes the value used when the key is unset
+	Default string
+	get     func(c *Config) string
+	// validate rejects values that would break go-worktree; nil accepts
+	// any non-empty value
+	validate func(value string) error
+}
+
+// Settings lists the keys that can be read and written, in file order.
+// List-valued, map-valued, and boolean keys such as copy_on_create,
+// aliases, and lowercase_repo_name are edited in the file directly.
+var Settings = []Setting{
+	{Key: "base_path", Default: "~/worktrees", get: func(c *Config) string { return c.BasePath }, validate: validatePath},
+	{Key: "default_base_branch", Default: DefaultBaseBranch, get: func(c *Config) string { return c.DefaultBaseBranch }, validate: validateRefName},
+	{Key: "branch_prefix", get: func(c *Config) string { return c.BranchPrefix }, validate: validateRefName},
+	{Key: "template_dir", get: func(c *Config) string { return c.TemplateDir }, validate: validatePath},
+	{Key: "post_create_hook", get: func(c *Config) string { return c.PostCreateHook }},
+	{Key: "editor", Default: "$EDITOR", get: func(c *Config) string { return c.Editor }},
+	{Key: "ticket_pattern", get: func(c *Config) string { return c.TicketPattern }, validate: validatePattern},
+	{Key: "repo_key", Default: RepoKeyBasename, get: func(c *Config) string { return c.RepoKey }, validate: validateRepoKey},
+	{Key: "namespace_from", Default: NamespaceFromOrigin, get: func(c *Config) string { return c.NamespaceFrom }, validate: validateNamespaceFrom},
+	{Key: "dir_template", Default: DefaultDirTemplate, get: func(c *Config) string { return c.DirTemplate }, validate: validateDirTemplate},
+	{Key: "remote", Default: DefaultRemote, get: func(c *Config) string { return c.Remote }, validate: validateRefName},
+	{Key: "fetch_timeout", Default: "30s", get: func(c *Config) string {
+		if c.FetchTimeout == 0 {
+			return ""
+		}
+		return c.FetchTimeout.String()
+	}, validate: validateDuration},
+	{Key: "git_path", Default: "git", get: func(c *Config) string { return c.GitPath }},
+}
+
+// LookupSetting returns the setting for key
+func LookupSetting(key string) (Setting, error) {
+	var keys []string
+	for _, s := range Settings {
+		if s.Key == key {
+			return s, nil
+		}
+		keys = append(keys, s.Key)
+	}
+	return Setting{}, fmt.Errorf("unknown config key %q, expected one of: %s", key, strings.Join(keys, ", "))
+}
+
+// Get returns the configured value of the setting, or "" when unset
+func (s Setting) Get(c *Config) string {
+	return s.get(c)
+}
+
+// Validate returns an error if value is not acceptable for the setting
+func (s Setting) Validate(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%s cannot be empty", s.Key)
+	}
+	if s.validate == nil {
+		return nil
+	}
+	if err := s.validate(value); err != nil {
+		return fmt.Errorf("invalid %s %q: %w", s.Key, value, err)
+	}
+	return nil
+}
+
+// Set validates value and writes it for key to the config file at path,
+// creating the file if needed. Comments and other keys are preserved.
+func Set(path, key, value string) error {
+	setting, err := LookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if err := setting.Validate(value); err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		// An empty or comment-only file
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to update config file %s: expected a mapping of keys to values", path)
+	}
+	setMappingValue(root, key, value)
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// setMappingValue sets key to a string value in a YAML mapping node,
+// replacing an existing entry in place or appending a new one
+func setMappingValue(mapping *yaml.Node, key, value string) {
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			// Keep the comment written next to the old value
+			valueNode.LineComment = mapping.Content[i+1].LineComment
+			mapping.Content[i+1] = valueNode
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+}
+
+// validatePath accepts anything that can name a directory, rejecting
+// paths to existing files
+func validatePath(value string) error {
+	if strings.ContainsAny(value, "\x00\n") {
+		return errors.New("must be a single-line path")
+	}
+	expanded := value
+	if home, err := os.UserHomeDir(); err == nil && util.HasHomePrefix(value) {
+		expanded = filepath.Join(home, strings.TrimPrefix(value, "~"))
+	}
+	if info, err := os.Stat(expanded); err == nil && !info.IsDir() {
+		return errors.New("exists and is not a directory")
+	}
+	return nil
+}
+
+// validateRefName rejects values git would not accept in a branch or
+// remote name
+func validateRefName(value string) error {
+	if strings.ContainsAny(value, " \t\n~^:?*[\\") || strings.Contains(value, "..") {
+		return errors.New("contains characters not allowed in git names")
+	}
+	return nil
+}
+
+// validatePattern requires a valid regular expression
+func validatePattern(value string) error {
+	_, err := regexp.Compile(value)
+	return err
+}
+
+// validateDirTemplate requires a template that uses the ticket, so
+// directories can be matched back to their tickets
+func validateDirTemplate(value string) error {
+	if _, err := template.New("dir_template").Parse(value); err != nil {
+		return err
+	}
+	if !strings.Contains(value, ".Ticket") {
+		return errors.New("must include {{.Ticket}}")
+	}
+	return nil
+}
+
+// validateRepoKey requires one of the repository key strategies
+func validateRepoKey(value string) error {
+	if value != RepoKeyBasename && value != RepoKeyRemote {
+		return fmt.Errorf("expected %q or %q", RepoKeyBasename, RepoKeyRemote)
+	}
+	return nil
+}
+
+// validateNamespaceFrom requires one of the namespace remotes
+func validateNamespaceFrom(value string) error {
+	if value != NamespaceFromOrigin && value != NamespaceFromUpstream {
+		return fmt.Errorf("expected %q or %q", NamespaceFromOrigin, NamespaceFromUpstream)
+	}
+	return nil
+}
+
+// validateDuration requires a positive duration such as "45s" or "2m"
+func validateDuration(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return errors.New("expected a duration such as 45s or 2m")
+	}
+	if d <= 0 {
+		return errors.New("must be positive")
+	}
+	return nil
+}
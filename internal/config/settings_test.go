@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSet tests replacing and adding keys while keeping comments and
+// the rest of the file
+func TestSet(t *testing.T) {
+	path := writeConfig(t, `# team defaults
+base_path: /tmp/wt # shared disk
+copy_on_create:
+  - .env
+`)
+
+	if err := Set(path, "base_path", "~/src/worktrees"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := Set(path, "fetch_timeout", "1m"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	for _, want := range []string{"# team defaults", "base_path: ~/src/worktrees # shared disk", "\n  - .env\n", "fetch_timeout: 1m"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected config to contain %q, got:\n%s", want, data)
+		}
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.BasePath != "~/src/worktrees" || cfg.FetchTimeout != time.Minute || len(cfg.CopyOnCreate) != 1 {
+		t.Errorf("Unexpected config after set: %+v", cfg)
+	}
+}
+
+// TestSetNewFile tests creating the config file and its directory
+func TestSetNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go-worktree", "config.yaml")
+	if err := Set(path, "ticket_pattern", `^[A-Z]+-\d+$`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.TicketPattern != `^[A-Z]+-\d+$` {
+		t.Errorf("Expected ticket pattern to round-trip, got %q", cfg.TicketPattern)
+	}
+}
+
+// TestSetInvalid tests that unknown keys and bad values are rejected
+// without touching the file
+func TestSetInvalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	path := writeConfig(t, "editor: vim\n")
+
+	testCases := []struct {
+		key   string
+		value string
+	}{
+		{"colour", "red"},
+		{"copy_on_create", ".env"},
+		{"editor", " "},
+		{"base_path", file},
+		{"default_base_branch", "my branch"},
+		{"repo_key", "path"},
+		{"namespace_from", "fork"},
+		{"fetch_timeout", "soon"},
+		{"fetch_timeout", "-5s"},
+		{"ticket_pattern", "[A-Z"},
+		{"dir_template", "{{.Ticket"},
+		{"dir_template", "{{.Date}}"},
+	}
+
+	for _, tc := range testCases {
+		if err := Set(path, tc.key, tc.value); err == nil {
+			t.Errorf("Expected error setting %s to %q", tc.key, tc.value)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if string(data) != "editor: vim\n" {
+		t.Errorf("Expected config to be unchanged, got %q", data)
+	}
+}
+
+// TestSettingGet tests reading configured values and unset keys
+func TestSettingGet(t *testing.T) {
+	cfg := &Config{BranchPrefix: "feature/", FetchTimeout: 90 * time.Second}
+	testCases := []struct {
+		key      string
+		expected string
+	}{
+		{"branch_prefix", "feature/"},
+		{"fetch_timeout", "1m30s"},
+		{"editor", ""},
+	}
+
+	for _, tc := range testCases {
+		setting, err := LookupSetting(tc.key)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if value := setting.Get(cfg); value != tc.expected {
+			t.Errorf("Get(%s): expected %q, got %q", tc.key, tc.expected, value)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Common git failures, matched with errors.Is on the errors Client methods
+// return
+var (
+	// ErrBranchNotMerged means git branch -d refused to delete a branch
+	// with commits that are not merged
+	ErrBranchNotMerged = errors.New("branch is not fully merged")
+	// ErrBranchInUse means a branch can't be deleted because a worktree
+	// has it checked out
+	ErrBranchInUse = errors.New("branch is checked out in a worktree")
+	// ErrBranchNotFound means the branch doesn't exist
+	ErrBranchNotFound = errors.New("branch not found")
+	// ErrWorktreeDirty means git refused to remove a worktree with
+	// modified or untracked files
+	ErrWorktreeDirty = errors.New("worktree contains modified or untracked files")
+	// ErrWorktreeLocked means git refused to remove a locked worktree
+	ErrWorktreeLocked = errors.New("worktree is locked")
+)
+
+// KnownError is a git failure recognised from its error output, described
+// in plain words with a suggestion for getting past it. The *CommandError
+// with git's own output is available through errors.Unwrap.
+type KnownError struct {
+	// Kind is the sentinel error the failure matches, such as
+	// ErrBranchNotMerged
+	Kind error
+	// Message says what went wrong
+	Message string
+	// Hint suggests how to get past it, and may be empty
+	Hint string
+	Err  *CommandError
+}
+
+func (e *KnownError) Error() string {
+	if e.Hint == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, e.Hint)
+}
+
+func (e *KnownError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the error's Kind
+func (e *KnownError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// failureSignature recognises a git failure from its error output. Git's
+// wording and capitalisation differ between versions, so patterns are
+// case-insensitive and cover the known variants.
+type failureSignature struct {
+	pattern *regexp.Regexp
+	kind    error
+	// describe returns the message and hint from the pattern's submatches
+	describe func(m []string) (message, hint string)
+}
+
+var failureSignatures = []failureSignature{
+	{
+		pattern: regexp.MustCompile(`(?i)the branch '([^']+)' is not fully merged`),
+		kind:    ErrBranchNotMerged,
+		describe: func(m []string) (string, string) {
+			return fmt.Sprintf("branch %s has commits that are not merged", m[1]),
+				"delete it with git branch -D to discard them"
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)cannot delete branch '([^']+)' (?:checked out|used by worktree) at '([^']+)'`),
+		kind:    ErrBranchInUse,
+		describe: func(m []string) (string, string) {
+			return fmt.Sprintf("branch %s is checked out in %s", m[1], m[2]),
+				"remove that worktree or switch it to another branch first"
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)branch '([^']+)' not found`),
+		kind:    ErrBranchNotFound,
+		describe: func(m []string) (string, string) {
+			return fmt.Sprintf("branch %s does not exist", m[1]), ""
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)'([^']+)' contains modified or untracked files`),
+		kind:    ErrWorktreeDirty,
+		describe: func(m []string) (string, string) {
+			return fmt.Sprintf("worktree %s has uncommitted changes", m[1]),
+				"remove it with --force to discard them"
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)cannot remove a locked working tree`),
+		kind:    ErrWorktreeLocked,
+		describe: func(m []string) (string, string) {
+			return "the worktree is locked", "unlock it first"
+		},
+	},
+}
+
+// classify returns a *KnownError wrapping err when its output matches a
+// known failure, and err itself otherwise
+func classify(err *CommandError) error {
+	for _, sig := range failureSignatures {
+		if m := sig.pattern.FindStringSubmatch(err.Stderr); m != nil {
+			message, hint := sig.describe(m)
+			return &KnownError{Kind: sig.kind, Message: message, Hint: hint, Err: err}
+		}
+	}
+	return err
+}
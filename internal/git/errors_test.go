@@ -0,0 +1,91 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestClassify tests recognising common git failures from canned error
+// output of older and newer git versions
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		stderr   string
+		kind     error
+		expected string
+	}{
+		{
+			"error: The branch 'ABC-746' is not fully merged.\nIf you are sure you want to delete it, run 'git branch -D ABC-746'.\n",
+			ErrBranchNotMerged,
+			"branch ABC-746 has commits that are not merged (delete it with git branch -D to discard them)",
+		},
+		{
+			"error: the branch 'ABC-746' is not fully merged\nhint: If you are sure you want to delete it, run 'git branch -D ABC-746'\n",
+			ErrBranchNotMerged,
+			"branch ABC-746 has commits that are not merged (delete it with git branch -D to discard them)",
+		},
+		{
+			"error: Cannot delete branch 'ABC-746' checked out at '/wt/repo/ABC-746'\n",
+			ErrBranchInUse,
+			"branch ABC-746 is checked out in /wt/repo/ABC-746 (remove that worktree or switch it to another branch first)",
+		},
+		{
+			"error: cannot delete branch 'ABC-746' used by worktree at '/wt/repo/ABC-746'\n",
+			ErrBranchInUse,
+			"branch ABC-746 is checked out in /wt/repo/ABC-746 (remove that worktree or switch it to another branch first)",
+		},
+		{
+			"error: branch 'nope' not found.\n",
+			ErrBranchNotFound,
+			"branch nope does not exist",
+		},
+		{
+			"fatal: '/wt/repo/ABC-746' contains modified or untracked files, use --force to delete it\n",
+			ErrWorktreeDirty,
+			"worktree /wt/repo/ABC-746 has uncommitted changes (remove it with --force to discard them)",
+		},
+		{
+			"fatal: cannot remove a locked working tree;\nuse 'remove -f -f' to override or unlock first\n",
+			ErrWorktreeLocked,
+			"the worktree is locked (unlock it first)",
+		},
+	}
+
+	for _, tc := range testCases {
+		cmdErr := &CommandError{Args: []string{"branch"}, Stderr: tc.stderr, Err: errors.New("exit status 1")}
+		err := classify(cmdErr)
+		if !errors.Is(err, tc.kind) {
+			t.Errorf("For %q expected %v, got %v", tc.stderr, tc.kind, err)
+			continue
+		}
+		if err.Error() != tc.expected {
+			t.Errorf("Expected %q, got %q", tc.expected, err.Error())
+		}
+		if errors.Unwrap(err) != cmdErr {
+			t.Errorf("Expected the git error to be unwrapped from %v", err)
+		}
+		if !errors.Is(err, ErrCommandFailed) {
+			t.Errorf("Expected %v to still match ErrCommandFailed", err)
+		}
+	}
+
+	cmdErr := &CommandError{Stderr: "fatal: not a working tree\n", Err: errors.New("exit status 128")}
+	if err := classify(cmdErr); err != cmdErr {
+		t.Errorf("Expected an unknown failure to be returned as it is, got %v", err)
+	}
+}
+
+// TestKnownErrorFromClient tests that a failing command returns the typed
+// error
+func TestKnownErrorFromClient(t *testing.T) {
+	initTestRepo(t, "main")
+
+	err := NewClient().DeleteBranch(context.Background(), "main")
+	if !errors.Is(err, ErrBranchInUse) {
+		t.Fatalf("Expected ErrBranchInUse, got %v", err)
+	}
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Stderr == "" {
+		t.Errorf("Expected git's output to be kept, got %#v", cmdErr)
+	}
+}
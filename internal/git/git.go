@@ -2,102 +2,854 @@
 package git
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
 )
 
+// ErrNotARepo is returned when an operation needs a git repository and the
+// current directory is not inside one
+var ErrNotARepo = errors.New("not in a git repository")
+
+// ErrCommandFailed matches any *CommandError with errors.Is
+var ErrCommandFailed = errors.New("git command failed")
+
+// CommandError is returned when a git command exits unsuccessfully. Its
+// message is git's error output.
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	message := errorMessage(e.Stderr)
+	if message == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s (%v)", message, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrCommandFailed
+func (e *CommandError) Is(target error) bool {
+	return target == ErrCommandFailed
+}
+
+// errorMessage extracts the meaningful part of git's stderr. When git
+// reports "fatal:" or "error:" lines, only those are kept so progress
+// output such as "Preparing worktree" is dropped.
+func errorMessage(stderr string) string {
+	var errorLines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "fatal: ") || strings.HasPrefix(line, "error: ") {
+			errorLines = append(errorLines, line)
+		}
+	}
+	if len(errorLines) > 0 {
+		return strings.Join(errorLines, "\n")
+	}
+	return strings.TrimSpace(stderr)
+}
+
 // Worktree represents a git worktree
 type Worktree struct {
-	Path   string
-	Branch string
+	Path     string
+	Head     string
+	Branch   string
+	Detached bool
+	Bare     bool
+	// Locked marks a worktree protected from pruning by git worktree lock,
+	// LockReason holds the reason given when it was locked, if any
+	Locked     bool
+	LockReason string
+}
+
+// Status describes the working tree state of a worktree
+type Status struct {
+	Branch   string
+	Modified int
+}
+
+// Clean reports whether the worktree has no uncommitted changes
+func (s Status) Clean() bool {
+	return s.Modified == 0
+}
+
+// DefaultFetchTimeout bounds how long a fetch may take before it is aborted
+const DefaultFetchTimeout = 30 * time.Second
+
+// DefaultFetchRetries is how many times a fetch that failed with a network
+// error is retried by default
+const DefaultFetchRetries = 2
+
+// DefaultFetchBackoff is the wait before the first fetch retry; it doubles
+// for each retry after that
+const DefaultFetchBackoff = time.Second
+
+// DefaultBinary is the git executable run when none is configured
+const DefaultBinary = "git"
+
+// BinaryEnv names the environment variable that selects the git executable,
+// taking precedence over the configured one
+const BinaryEnv = "GIT_BINARY"
+
+// ResolveBinary returns the git executable to run: $GIT_BINARY when set,
+// then configured, then DefaultBinary
+func ResolveBinary(configured string) string {
+	if env := os.Getenv(BinaryEnv); env != "" {
+		return env
+	}
+	if configured != "" {
+		return configured
+	}
+	return DefaultBinary
 }
 
-// Client wraps git command operations
-type Client struct{}
+// Client wraps git command operations. Every operation takes a context;
+// cancelling it kills the running git process.
+type Client struct {
+	// binary is the git executable every command runs
+	binary string
+	// logger receives each git command line before it runs; nil disables logging
+	logger io.Writer
+	// fetchTimeout limits each network fetch attempt; zero means no limit
+	fetchTimeout time.Duration
+	// fetchBackoff is the wait before the first fetch retry
+	fetchBackoff time.Duration
+	// dryRun receives the commands that would change the repository instead
+	// of running them; nil runs them
+	dryRun io.Writer
+}
 
-// NewClient creates a new git client
+// NewClient creates a new git client running the executable named by
+// $GIT_BINARY, or git from PATH
 func NewClient() *Client {
-	return &Client{}
+	return &Client{binary: ResolveBinary(""), fetchTimeout: DefaultFetchTimeout, fetchBackoff: DefaultFetchBackoff}
+}
+
+// SetBinary changes the git executable the client runs, either a path or a
+// name looked up on PATH
+func (c *Client) SetBinary(binary string) {
+	c.binary = binary
+}
+
+// SetFetchTimeout changes how long FetchBranch may run; zero disables the
+// limit
+func (c *Client) SetFetchTimeout(d time.Duration) {
+	c.fetchTimeout = d
+}
+
+// SetFetchBackoff changes the wait before the first fetch retry
+func (c *Client) SetFetchBackoff(d time.Duration) {
+	c.fetchBackoff = d
+}
+
+// SetLogger makes the client echo each git command it runs to w
+func (c *Client) SetLogger(w io.Writer) {
+	c.logger = w
+}
+
+// SetDryRun makes the client write each command that would change the
+// repository, such as adding a worktree or deleting a branch, to w instead
+// of running it. Read-only commands still run. A nil w turns dry-run off.
+func (c *Client) SetDryRun(w io.Writer) {
+	c.dryRun = w
+}
+
+// command builds a git command, logging it when a logger is set. All git
+// invocations go through here.
+func (c *Client) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, c.binary, args...)
+	if c.logger != nil {
+		fmt.Fprintf(c.logger, "+ %s\n", strings.Join(cmd.Args, " "))
+	}
+	return cmd
+}
+
+// run runs a git command that changes the repository, discarding its output
+// unless it fails. In dry-run mode the command is only printed.
+func (c *Client) run(ctx context.Context, args ...string) error {
+	if c.dryRun != nil {
+		fmt.Fprintf(c.dryRun, "[dry-run] %s\n", util.ShellJoin(append([]string{c.binary}, args...)...))
+		return nil
+	}
+	_, _, err := c.runCapture(ctx, args...)
+	return err
+}
+
+// output runs a git command and returns its standard output
+func (c *Client) output(ctx context.Context, args ...string) (string, error) {
+	stdout, _, err := c.runCapture(ctx, args...)
+	return stdout, err
+}
+
+// runCapture runs a git command, capturing standard output and standard
+// error separately. Errors carry only stderr, and common failures are
+// returned as a *KnownError. When a logger is set, stderr
+// is also streamed to it so progress is visible in verbose mode.
+func (c *Client) runCapture(ctx context.Context, args ...string) (stdout, stderr string, err error) {
+	var outBuf, errBuf bytes.Buffer
+	cmd := c.command(ctx, args...)
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	if c.logger != nil {
+		cmd.Stderr = io.MultiWriter(&errBuf, c.logger)
+	}
+
+	if err := cmd.Run(); err != nil {
+		return outBuf.String(), errBuf.String(), classify(&CommandError{Args: args, Stderr: errBuf.String(), Err: contextErr(ctx, err)})
+	}
+	return outBuf.String(), errBuf.String(), nil
+}
+
+// contextErr returns the context's error when it was cancelled or timed out,
+// since git then fails with an unhelpful "signal: killed", and err otherwise
+func contextErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// Version returns the installed git version, e.g. "2.43.0"
+func (c *Client) Version(ctx context.Context) (string, error) {
+	output, err := c.output(ctx, "--version")
+	if err != nil {
+		return "", fmt.Errorf("failed to run git: %w", err)
+	}
+	return strings.TrimPrefix(strings.TrimSpace(output), "git version "), nil
+}
+
+// IsInsideRepo reports whether the current directory is inside a git
+// repository. An error is returned only if git itself could not be run.
+func (c *Client) IsInsideRepo(ctx context.Context) (bool, error) {
+	err := contextErr(ctx, c.command(ctx, "rev-parse", "--git-dir").Run())
+	if err == nil {
+		return true, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to run git: %w", err)
 }
 
-// GetRepoName gets the name of the current git repository
-func (c *Client) GetRepoName() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err := cmd.Output()
+// GetRepoName gets the name of the current git repository. The name comes
+// from the shared git directory, so it is the same in every worktree and
+// in bare repositories, which have no top-level directory.
+func (c *Client) GetRepoName(ctx context.Context) (string, error) {
+	bare, err := c.IsBareRepo(ctx)
+	if err != nil {
+		return "", err
+	}
+	// A linked worktree of a bare repository isn't bare itself, but shares
+	// the repository's core.bare setting
+	if !bare {
+		bare = c.coreBare(ctx)
+	}
+
+	commonDir, err := c.commonDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	if name := repoNameFromGitDir(commonDir, bare); name != "" {
+		return name, nil
+	}
+
+	repoPath, err := c.Toplevel(ctx)
 	if err != nil {
-		return "", fmt.Errorf("not in a git repository: %w", err)
+		return "", err
 	}
-	repoPath := strings.TrimSpace(string(output))
 	return filepath.Base(repoPath), nil
 }
 
-// FetchBranch fetches the latest changes for a branch
-func (c *Client) FetchBranch(branch string) error {
-	cmd := exec.Command("git", "fetch", "origin", branch)
+// IsBareRepo reports whether the current repository is bare
+func (c *Client) IsBareRepo(ctx context.Context) (bool, error) {
+	output, err := c.output(ctx, "rev-parse", "--is-bare-repository")
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrNotARepo, err)
+	}
+	return strings.TrimSpace(output) == "true", nil
+}
+
+// coreBare reports whether the repository's core.bare setting is true. It
+// is false when the setting is missing or can't be read.
+func (c *Client) coreBare(ctx context.Context) bool {
+	output, err := c.output(ctx, "config", "--bool", "core.bare")
+	return err == nil && strings.TrimSpace(output) == "true"
+}
+
+// commonDir returns the absolute path of the git directory shared by all
+// worktrees of the current repository
+func (c *Client) commonDir(ctx context.Context) (string, error) {
+	output, err := c.output(ctx, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrNotARepo, err)
+	}
+	return filepath.Abs(strings.TrimSpace(output))
+}
+
+// repoNameFromGitDir derives the repository name from its git directory:
+// the parent of a ".git" or ".bare" directory, or a bare repository's own
+// name without the ".git" suffix, which is stripped even when bare isn't
+// set. It returns "" when the name has to come from the working tree
+// instead.
+func repoNameFromGitDir(gitDir string, bare bool) string {
+	base := filepath.Base(gitDir)
+	switch {
+	case base == ".git" || base == ".bare":
+		return filepath.Base(filepath.Dir(gitDir))
+	case bare || strings.HasSuffix(base, ".git"):
+		return strings.TrimSuffix(base, ".git")
+	default:
+		return ""
+	}
+}
+
+// GetRepoIdentifier returns the "org/repo" identifier derived from the URL
+// of remote
+func (c *Client) GetRepoIdentifier(ctx context.Context, remote string) (string, error) {
+	remoteURL, err := c.RemoteURL(ctx, remote)
+	if err != nil {
+		return "", err
+	}
+	return ParseRemoteURL(remoteURL)
+}
+
+// RemoteURL returns the configured URL of remote
+func (c *Client) RemoteURL(ctx context.Context, remote string) (string, error) {
+	output, err := c.output(ctx, "config", "--get", "remote."+remote+".url")
+	if err != nil {
+		return "", fmt.Errorf("no %s remote configured: %w", remote, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// ParseRemoteHost returns the lowercased host name of a remote URL in
+// scp-like or scheme:// form, or "" for a local path
+func ParseRemoteHost(remoteURL string) string {
+	host := remoteURL
+	if i := strings.Index(host, "://"); i >= 0 {
+		// scheme://[user@]host[:port]/path
+		host = host[i+3:]
+		if slash := strings.Index(host, "/"); slash >= 0 {
+			host = host[:slash]
+		}
+	} else if colon := strings.Index(host, ":"); colon >= 0 && !strings.HasPrefix(host, "/") {
+		// [user@]host:path
+		host = host[:colon]
+	} else {
+		return ""
+	}
+
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	if colon := strings.Index(host, ":"); colon >= 0 {
+		host = host[:colon]
+	}
+	return strings.ToLower(host)
+}
+
+// ParseRemoteURL extracts "org/repo" from a remote URL in scp-like
+// (git@host:org/repo.git), https://, or ssh:// form
+func ParseRemoteURL(remoteURL string) (string, error) {
+	path := remoteURL
+	if i := strings.Index(path, "://"); i >= 0 {
+		// scheme://[user@]host[:port]/path
+		path = path[i+3:]
+		slash := strings.Index(path, "/")
+		if slash < 0 {
+			return "", fmt.Errorf("cannot parse remote URL %q", remoteURL)
+		}
+		path = path[slash+1:]
+	} else if colon := strings.Index(path, ":"); colon >= 0 && !strings.HasPrefix(path, "/") {
+		// [user@]host:path
+		path = path[colon+1:]
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", fmt.Errorf("cannot parse remote URL %q", remoteURL)
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1], nil
+}
+
+// Toplevel returns the absolute path of the current working tree's root,
+// the same from any subdirectory of it
+func (c *Client) Toplevel(ctx context.Context) (string, error) {
+	output, err := c.output(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrNotARepo, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// CurrentBranch returns the branch checked out in the current directory's
+// worktree. It fails when HEAD is detached.
+func (c *Client) CurrentBranch(ctx context.Context) (string, error) {
+	output, err := c.output(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("no current branch (HEAD is detached?): %w", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// UpstreamOf returns the upstream of branch as git abbreviates it, e.g.
+// "origin/release-2", or just the branch name for a local upstream. It
+// fails when branch has no upstream configured.
+func (c *Client) UpstreamOf(ctx context.Context, branch string) (string, error) {
+	output, err := c.output(ctx, "rev-parse", "--abbrev-ref", branch+"@{upstream}")
+	if err != nil {
+		return "", fmt.Errorf("branch %s has no upstream: %w", branch, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// DefaultBranch returns the default branch of remote, as recorded in
+// refs/remotes/REMOTE/HEAD when the repository was cloned. It fails when
+// the remote HEAD is unknown, e.g. for repositories that were not cloned.
+func (c *Client) DefaultBranch(ctx context.Context, remote string) (string, error) {
+	prefix := "refs/remotes/" + remote + "/"
+	output, err := c.output(ctx, "symbolic-ref", "--quiet", prefix+"HEAD")
+	if err != nil {
+		return "", fmt.Errorf("default branch of %s is unknown: %w", remote, err)
+	}
+	branch, ok := strings.CutPrefix(strings.TrimSpace(output), prefix)
+	if !ok || branch == "" {
+		return "", fmt.Errorf("unexpected HEAD for %s: %s", remote, strings.TrimSpace(output))
+	}
+	return branch, nil
+}
+
+// FetchOptions controls how FetchBranch fetches
+type FetchOptions struct {
+	// Depth limits the fetch to this many commits of history; zero fetches
+	// everything. Fetching with a depth makes the repository shallow.
+	Depth int
+	// Retries is how many more attempts are made after a fetch fails with a
+	// transient network error. Other failures, such as a missing branch, are
+	// never retried.
+	Retries int
+}
+
+// FetchBranch fetches the latest changes for a branch from remote. Each
+// attempt gives up after the client's fetch timeout; transient failures are
+// retried up to opts.Retries times, doubling the wait between attempts.
+func (c *Client) FetchBranch(ctx context.Context, remote, branch string, opts FetchOptions) error {
+	backoff := c.fetchBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.fetchOnce(ctx, remote, branch, opts)
+		if err == nil {
+			return nil
+		}
+		if attempt >= opts.Retries || ctx.Err() != nil || !isTransientFetchError(err) {
+			return fmt.Errorf("failed to fetch branch: %w", err)
+		}
+
+		if c.logger != nil {
+			fmt.Fprintf(c.logger, "fetch failed, retrying in %s (%d of %d): %v\n", backoff, attempt+1, opts.Retries, err)
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("failed to fetch branch: %w", ctx.Err())
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// fetchOnce makes a single fetch attempt bounded by the fetch timeout
+func (c *Client) fetchOnce(ctx context.Context, remote, branch string, opts FetchOptions) error {
+	if c.fetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.fetchTimeout)
+		defer cancel()
+	}
+	return c.run(ctx, fetchArgs(remote, branch, opts)...)
+}
+
+// transientFetchErrors are fragments of git's stderr that indicate a network
+// problem worth retrying, as opposed to a missing branch or remote
+var transientFetchErrors = []string{
+	"could not resolve host",
+	"couldn't resolve host",
+	"temporary failure in name resolution",
+	"connection refused",
+	"connection reset",
+	"connection timed out",
+	"operation timed out",
+	"failed to connect",
+	"network is unreachable",
+	"the remote end hung up unexpectedly",
+	"early eof",
+	"rpc failed",
+	"gnutls_handshake() failed",
+	"ssl_error_syscall",
+	"http/2 stream",
+	"the requested url returned error: 5",
+}
+
+// isTransientFetchError reports whether a failed fetch is worth retrying:
+// the attempt timed out or git's stderr points at a network problem
+func isTransientFetchError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	stderr := strings.ToLower(cmdErr.Stderr)
+	for _, fragment := range transientFetchErrors {
+		if strings.Contains(stderr, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchArgs returns the git arguments for fetching branch from remote
+func fetchArgs(remote, branch string, opts FetchOptions) []string {
+	args := []string{"fetch"}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	return append(args, remote, branch)
+}
+
+// CreateWorktree creates a new worktree with a new branch starting at
+// startPoint, a branch, tag, or commit. An empty startPoint uses HEAD.
+func (c *Client) CreateWorktree(ctx context.Context, path, branchName, startPoint string) error {
+	args := []string{"worktree", "add", path, "-b", branchName}
+	if startPoint != "" {
+		args = append(args, startPoint)
+	}
+	return c.run(ctx, args...)
+}
+
+// CreateTrackingWorktree creates a new worktree with a new branch that
+// starts at and tracks remote/branchName
+func (c *Client) CreateTrackingWorktree(ctx context.Context, path, branchName, remote string) error {
+	return c.run(ctx, "worktree", "add", "--track", "-b", branchName, path, remote+"/"+branchName)
+}
+
+// CreateBranch creates a branch starting at startPoint without checking it
+// out
+func (c *Client) CreateBranch(ctx context.Context, branchName, startPoint string) error {
+	return c.run(ctx, "branch", branchName, startPoint)
+}
+
+// AddWorktree creates a new worktree that checks out an existing branch
+func (c *Client) AddWorktree(ctx context.Context, path, branchName string) error {
+	return c.run(ctx, "worktree", "add", path, branchName)
+}
+
+// SetUpstream configures the branch checked out at path to track
+// remote/branch
+func (c *Client) SetUpstream(ctx context.Context, path, remote, branch string) error {
+	return c.run(ctx, "-C", path, "branch", "--set-upstream-to="+remote+"/"+branch)
+}
+
+// LocalBranchExists reports whether a local branch with the given name exists
+func (c *Client) LocalBranchExists(ctx context.Context, branchName string) (bool, error) {
+	err := contextErr(ctx, c.command(ctx, "show-ref", "--verify", "--quiet", "refs/heads/"+branchName).Run())
+	if err == nil {
+		return true, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check branch %s: %w", branchName, err)
+}
+
+// RemoteBranchExists reports whether the remote-tracking branch
+// remote/branch exists, as of the last fetch from remote
+func (c *Client) RemoteBranchExists(ctx context.Context, remote, branch string) (bool, error) {
+	err := contextErr(ctx, c.command(ctx, "show-ref", "--verify", "--quiet", "refs/remotes/"+remote+"/"+branch).Run())
+	if err == nil {
+		return true, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check branch %s/%s: %w", remote, branch, err)
+}
+
+// IsIgnored reports whether path is ignored by git. Tracked files are never
+// ignored, even when a .gitignore pattern matches them.
+func (c *Client) IsIgnored(ctx context.Context, path string) (bool, error) {
+	ignored, err := c.IgnoredPaths(ctx, []string{path})
+	if err != nil {
+		return false, err
+	}
+	return ignored[path], nil
+}
+
+// IgnoredPaths reports which of paths git ignores, checking them all with a
+// single git check-ignore. Tracked files are never ignored, even when a
+// .gitignore pattern matches them.
+func (c *Client) IgnoredPaths(ctx context.Context, paths []string) (map[string]bool, error) {
+	ignored := make(map[string]bool)
+	if len(paths) == 0 {
+		return ignored, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := c.command(ctx, "check-ignore", "--stdin", "-z")
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to fetch branch: %w", err)
+		// check-ignore exits with 1 when none of the paths is ignored
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return ignored, nil
+		}
+		return nil, fmt.Errorf("failed to check ignored files: %w",
+			classify(&CommandError{Args: cmd.Args[1:], Stderr: stderr.String(), Err: contextErr(ctx, err)}))
 	}
-	return nil
+
+	for _, path := range strings.Split(stdout.String(), "\x00") {
+		if path != "" {
+			ignored[path] = true
+		}
+	}
+	return ignored, nil
 }
 
-// CreateWorktree creates a new worktree with a new branch
-func (c *Client) CreateWorktree(path, branchName string) error {
-	cmd := exec.Command("git", "worktree", "add", path, "-b", branchName)
-	output, err := cmd.CombinedOutput()
+// ListLocalBranches returns the names of all local branches
+func (c *Client) ListLocalBranches(ctx context.Context) ([]string, error) {
+	output, err := c.output(ctx, "for-each-ref", "--format=%(refname)", "refs/heads/")
 	if err != nil {
-		return fmt.Errorf("%s: %w", string(output), err)
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var branches []string
+	for _, line := range strings.Split(output, "\n") {
+		if branch, ok := strings.CutPrefix(strings.TrimSpace(line), "refs/heads/"); ok {
+			branches = append(branches, branch)
+		}
 	}
-	return nil
+	return branches, nil
 }
 
-// RemoveWorktree removes a worktree
-func (c *Client) RemoveWorktree(path string) error {
-	cmd := exec.Command("git", "worktree", "remove", path)
-	output, err := cmd.CombinedOutput()
+// MergedBranches returns the names of the local branches whose tips are
+// reachable from base, so merging them into base would add nothing. A
+// branch without commits of its own is included.
+func (c *Client) MergedBranches(ctx context.Context, base string) ([]string, error) {
+	output, err := c.output(ctx, "branch", "--merged", base, "--format=%(refname)")
 	if err != nil {
-		return fmt.Errorf("%s: %w", string(output), err)
+		return nil, fmt.Errorf("failed to list branches merged into %s: %w", base, err)
 	}
-	return nil
+
+	var branches []string
+	for _, line := range strings.Split(output, "\n") {
+		if branch, ok := strings.CutPrefix(strings.TrimSpace(line), "refs/heads/"); ok {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
 }
 
-// DeleteBranch deletes a branch
-func (c *Client) DeleteBranch(branchName string) error {
-	cmd := exec.Command("git", "branch", "-D", branchName)
-	output, err := cmd.CombinedOutput()
+// BranchCheckedOutAt returns the path of the worktree that has branch
+// checked out, or "" when no worktree does. Git refuses to check out a
+// branch in two worktrees at once.
+func (c *Client) BranchCheckedOutAt(ctx context.Context, branch string) (string, error) {
+	worktrees, err := c.ListWorktrees(ctx)
 	if err != nil {
-		return fmt.Errorf("%s: %w", string(output), err)
+		return "", err
+	}
+	for _, wt := range worktrees {
+		if !wt.Detached && wt.Branch == branch {
+			return wt.Path, nil
+		}
+	}
+	return "", nil
+}
+
+// BranchExists reports whether ref names a commit, such as a local branch
+// ("main"), a remote-tracking branch ("origin/main"), a tag, or a SHA
+func (c *Client) BranchExists(ctx context.Context, ref string) (bool, error) {
+	err := contextErr(ctx, c.command(ctx, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Run())
+	if err == nil {
+		return true, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
 	}
-	return nil
+	return false, fmt.Errorf("failed to check branch %s: %w", ref, err)
+}
+
+// IsDirtyWorktreeError reports whether err came from git refusing to remove
+// a worktree that has uncommitted changes. Errors that didn't come from a
+// Client are recognised by git's message.
+func IsDirtyWorktreeError(err error) bool {
+	return errors.Is(err, ErrWorktreeDirty) || err != nil && strings.Contains(err.Error(), "contains modified or untracked files")
+}
+
+// IsLockedWorktreeError reports whether err came from git refusing to
+// remove a locked worktree. Errors that didn't come from a Client are
+// recognised by git's message.
+func IsLockedWorktreeError(err error) bool {
+	return errors.Is(err, ErrWorktreeLocked) || err != nil && strings.Contains(err.Error(), "cannot remove a locked working tree")
+}
+
+// RemoveWorktree removes a worktree. With force set, uncommitted changes
+// are discarded.
+func (c *Client) RemoveWorktree(ctx context.Context, path string, force bool) error {
+	args := []string{"worktree", "remove", path}
+	if force {
+		args = append(args, "--force")
+	}
+	return c.run(ctx, args...)
+}
+
+// MoveWorktree moves a worktree to a new path
+func (c *Client) MoveWorktree(ctx context.Context, oldPath, newPath string) error {
+	return c.run(ctx, "worktree", "move", oldPath, newPath)
+}
+
+// LockWorktree locks the worktree at path so git worktree prune leaves it
+// alone, recording reason when it is not empty
+func (c *Client) LockWorktree(ctx context.Context, path, reason string) error {
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	return c.run(ctx, append(args, path)...)
+}
+
+// UnlockWorktree removes the lock from the worktree at path
+func (c *Client) UnlockWorktree(ctx context.Context, path string) error {
+	return c.run(ctx, "worktree", "unlock", path)
+}
+
+// RenameBranch renames a local branch
+func (c *Client) RenameBranch(ctx context.Context, oldName, newName string) error {
+	return c.run(ctx, "branch", "-m", oldName, newName)
+}
+
+// DeleteBranch deletes a branch
+func (c *Client) DeleteBranch(ctx context.Context, branchName string) error {
+	return c.run(ctx, "branch", "-D", branchName)
 }
 
 // ListWorktrees returns a list of all worktrees for the current repository
-func (c *Client) ListWorktrees() ([]Worktree, error) {
-	cmd := exec.Command("git", "worktree", "list")
-	output, err := cmd.Output()
+func (c *Client) ListWorktrees(ctx context.Context) ([]Worktree, error) {
+	output, err := c.output(ctx, "worktree", "list", "--porcelain")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
-	// Parse output
-	worktreeLines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return parseWorktreeList(output), nil
+}
+
+// parseWorktreeList parses the output of `git worktree list --porcelain`.
+// Each worktree is a block of "key value" lines separated by a blank line.
+func parseWorktreeList(output string) []Worktree {
 	var worktrees []Worktree
+	var current *Worktree
 
-	for _, line := range worktreeLines {
-		parts := strings.Fields(line)
-		if len(parts) < 3 {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			current = nil
 			continue
 		}
 
-		path := parts[0]
-		branch := strings.Trim(parts[2], "[]")
+		key, value, _ := strings.Cut(line, " ")
+		if key == "worktree" {
+			worktrees = append(worktrees, Worktree{Path: value})
+			current = &worktrees[len(worktrees)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
 
-		worktrees = append(worktrees, Worktree{
-			Path:   path,
-			Branch: branch,
-		})
+		switch key {
+		case "HEAD":
+			current.Head = value
+		case "branch":
+			current.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "detached":
+			current.Detached = true
+		case "bare":
+			current.Bare = true
+		case "locked":
+			current.Locked = true
+			current.LockReason = value
+		}
 	}
 
-	return worktrees, nil
+	return worktrees
+}
+
+// WorktreeStatus returns the branch and number of changed files in the
+// worktree at path
+func (c *Client) WorktreeStatus(ctx context.Context, path string) (Status, error) {
+	output, err := c.output(ctx, "-C", path, "status", "--porcelain", "--branch")
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to get status for %s: %w", path, err)
+	}
+
+	return parseStatus(output), nil
+}
+
+// parseStatus parses the output of `git status --porcelain --branch`
+func parseStatus(output string) Status {
+	var status Status
+
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		if header, ok := strings.CutPrefix(line, "## "); ok {
+			status.Branch = parseStatusBranch(header)
+			continue
+		}
+		status.Modified++
+	}
+
+	return status
+}
+
+// parseStatusBranch extracts the branch name from a porcelain status header
+// such as "main...origin/main [ahead 1]" or "HEAD (no branch)"
+func parseStatusBranch(header string) string {
+	if strings.HasPrefix(header, "HEAD (no branch)") {
+		return "detached"
+	}
+	if branch, ok := strings.CutPrefix(header, "No commits yet on "); ok {
+		return branch
+	}
+	branch, _, _ := strings.Cut(header, "...")
+	branch, _, _ = strings.Cut(branch, " ")
+	return branch
 }
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// OpenURL opens url in the user's default browser
+func OpenURL(url string) error {
+	name, args := browserCommand(runtime.GOOS, url)
+	if err := exec.Command(name, args...).Run(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	return nil
+}
+
+// browserCommand returns the command that opens url on the given OS:
+// open on macOS, start on Windows, and xdg-open elsewhere
+func browserCommand(goos, url string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}
+	case "windows":
+		// start is a cmd builtin; its first quoted argument is the window title
+		return "cmd", []string{"/c", "start", "", url}
+	default:
+		return "xdg-open", []string{url}
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestBrowserCommand tests picking the URL opener for each OS
+func TestBrowserCommand(t *testing.T) {
+	url := "https://github.com/acme/app/compare/main...ABC-746"
+	testCases := []struct {
+		goos     string
+		expected []string
+	}{
+		{"darwin", []string{"open", url}},
+		{"windows", []string{"cmd", "/c", "start", "", url}},
+		{"linux", []string{"xdg-open", url}},
+		{"freebsd", []string{"xdg-open", url}},
+	}
+
+	for _, tc := range testCases {
+		name, args := browserCommand(tc.goos, url)
+		if got := append([]string{name}, args...); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%s: expected %v, got %v", tc.goos, tc.expected, got)
+		}
+	}
+}
@@ -1,6 +1,13 @@
 // Package util provides utility functions
 package util
 
+import (
+	"os"
+	"regexp"
+
+	"golang.org/x/term"
+)
+
 // ANSI color codes for terminal output
 const (
 	ColorReset  = "\033[0m"
@@ -13,12 +20,56 @@ const (
 	ColorWhite  = "\033[37m"
 )
 
+// colorEnabled controls whether Colorize and Bold emit escape sequences
+var colorEnabled = true
+
+func init() {
+	// Follow the no-color.org convention and skip colors when not on a
+	// terminal, or on a Windows console that can't show them
+	_, noColor := os.LookupEnv("NO_COLOR")
+	colorEnabled = !noColor && IsTerminal(os.Stdout) && enableColorSupport(os.Stdout)
+	if colorEnabled && IsTerminal(os.Stderr) {
+		// Errors and progress messages are colored too
+		enableColorSupport(os.Stderr)
+	}
+}
+
+// SetColorEnabled turns colored output on or off
+func SetColorEnabled(enabled bool) {
+	colorEnabled = enabled
+}
+
+// ColorEnabled reports whether colored output is enabled
+func ColorEnabled() bool {
+	return colorEnabled
+}
+
+// IsTerminal reports whether f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
 // Colorize returns a string with color codes
 func Colorize(text, color string) string {
+	if !colorEnabled {
+		return text
+	}
 	return color + text + ColorReset
 }
 
 // Bold returns a string in bold
 func Bold(text string) string {
+	if !colorEnabled {
+		return text
+	}
 	return "\033[1m" + text + "\033[0m"
 }
+
+// sgrSequence matches an ANSI SGR escape sequence such as "\033[1;31m"
+var sgrSequence = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// StripColors removes the ANSI color and style codes from s, for strings
+// that were colored before colors could be turned off
+func StripColors(s string) string {
+	return sgrSequence.ReplaceAllString(s, "")
+}
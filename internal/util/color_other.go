@@ -0,0 +1,11 @@
+//go:build !windows
+
+package util
+
+import "os"
+
+// enableColorSupport reports whether the terminal f writes to understands
+// ANSI escapes, which every supported terminal outside Windows does
+func enableColorSupport(f *os.File) bool {
+	return true
+}
@@ -6,6 +6,9 @@ import (
 
 // TestColorize tests the Colorize function
 func TestColorize(t *testing.T) {
+	defer SetColorEnabled(ColorEnabled())
+	SetColorEnabled(true)
+
 	testCases := []struct {
 		text     string
 		color    string
@@ -26,6 +29,9 @@ func TestColorize(t *testing.T) {
 
 // TestBold tests the Bold function
 func TestBold(t *testing.T) {
+	defer SetColorEnabled(ColorEnabled())
+	SetColorEnabled(true)
+
 	testCases := []struct {
 		text     string
 		expected string
@@ -42,3 +48,41 @@ func TestBold(t *testing.T) {
 		}
 	}
 }
+
+// TestColorDisabled tests that disabling colors returns plain text
+func TestColorDisabled(t *testing.T) {
+	defer SetColorEnabled(ColorEnabled())
+	SetColorEnabled(false)
+
+	if result := Colorize("test", ColorRed); result != "test" {
+		t.Errorf("Expected %q, got %q", "test", result)
+	}
+	if result := Bold("test"); result != "test" {
+		t.Errorf("Expected %q, got %q", "test", result)
+	}
+}
+
+// TestStripColors tests removing color codes from colored strings
+func TestStripColors(t *testing.T) {
+	defer SetColorEnabled(ColorEnabled())
+	SetColorEnabled(true)
+
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{"", ""},
+		{"plain", "plain"},
+		{Colorize("test", ColorRed), "test"},
+		{Bold(Colorize("nested", ColorBlue)), "nested"},
+		{Colorize("a", ColorGreen) + " and " + Colorize("b", ColorYellow), "a and b"},
+		{"\033[1;31mbold red\033[0m", "bold red"},
+		{"\033[m", ""},
+	}
+
+	for _, tc := range testCases {
+		if result := StripColors(tc.text); result != tc.expected {
+			t.Errorf("For %q expected %q, got %q", tc.text, tc.expected, result)
+		}
+	}
+}
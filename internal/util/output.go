@@ -0,0 +1,88 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Verbosity controls how much informational output is printed
+type Verbosity int
+
+// Verbosity levels; the zero value is normal output
+const (
+	VerbosityQuiet Verbosity = iota - 1
+	VerbosityNormal
+	VerbosityVerbose
+)
+
+var (
+	verbosity Verbosity = VerbosityNormal
+	// infoOutput is where informational messages are written. They go to
+	// stderr with warnings and errors, leaving stdout for the data a
+	// command prints, such as a path, a listing, or JSON, so that it can
+	// be captured or piped.
+	infoOutput io.Writer = os.Stderr
+	// errOutput is where warnings and errors are written
+	errOutput io.Writer = os.Stderr
+)
+
+// SetVerbosity sets the package-wide verbosity level
+func SetVerbosity(v Verbosity) {
+	verbosity = v
+}
+
+// Quiet reports whether informational output is suppressed
+func Quiet() bool {
+	return verbosity <= VerbosityQuiet
+}
+
+// Verbose reports whether verbose output is enabled
+func Verbose() bool {
+	return verbosity >= VerbosityVerbose
+}
+
+// InfoWriter returns the writer for informational output, which discards
+// everything in quiet mode
+func InfoWriter() io.Writer {
+	if Quiet() {
+		return io.Discard
+	}
+	return infoOutput
+}
+
+// Infof prints an informational message unless quiet mode is enabled
+func Infof(format string, args ...any) {
+	fmt.Fprintf(InfoWriter(), format, args...)
+}
+
+// Success prints a message after a green "Success!" unless quiet mode is
+// enabled
+func Success(format string, args ...any) {
+	SuccessTo(InfoWriter(), format, args...)
+}
+
+// SuccessTo writes a message after a green "Success!" to w
+func SuccessTo(w io.Writer, format string, args ...any) {
+	fmt.Fprintf(w, "%s %s", Colorize("Success!", ColorGreen), fmt.Sprintf(format, args...))
+}
+
+// Warn prints a message after a yellow "Warning:" to stderr. Warnings are
+// shown in quiet mode too.
+func Warn(format string, args ...any) {
+	WarnTo(errOutput, format, args...)
+}
+
+// WarnTo writes a message after a yellow "Warning:" to w
+func WarnTo(w io.Writer, format string, args ...any) {
+	fmt.Fprintf(w, "%s %s", Colorize("Warning:", ColorYellow), fmt.Sprintf(format, args...))
+}
+
+// Errorf prints a message starting with "Error:" to stderr, all in red
+// but for the trailing newline
+func Errorf(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	line, _ := strings.CutSuffix(message, "\n")
+	fmt.Fprint(errOutput, Colorize("Error: "+line, ColorRed), message[len(line):])
+}
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+// TestInfof tests that informational output respects quiet mode
+func TestInfof(t *testing.T) {
+	defer SetVerbosity(verbosity)
+	defer func(w io.Writer) { infoOutput = w }(infoOutput)
+
+	var buf bytes.Buffer
+	infoOutput = &buf
+
+	SetVerbosity(VerbosityNormal)
+	Infof("hello %s\n", "world")
+	if buf.String() != "hello world\n" {
+		t.Errorf("Expected %q, got %q", "hello world\n", buf.String())
+	}
+
+	buf.Reset()
+	SetVerbosity(VerbosityQuiet)
+	Infof("hidden\n")
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output in quiet mode, got %q", buf.String())
+	}
+	if !Quiet() {
+		t.Errorf("Expected Quiet() to be true")
+	}
+}
+
+// TestInfoOutput tests that informational output goes to stderr, not
+// stdout
+func TestInfoOutput(t *testing.T) {
+	defer SetVerbosity(verbosity)
+	SetVerbosity(VerbosityNormal)
+	if InfoWriter() != os.Stderr {
+		t.Errorf("Expected informational output on stderr")
+	}
+}
+
+// TestMessages tests the labels and streams of success, warning, and error
+// messages
+func TestMessages(t *testing.T) {
+	defer SetVerbosity(verbosity)
+	defer SetColorEnabled(ColorEnabled())
+	defer func(info, errs io.Writer) { infoOutput, errOutput = info, errs }(infoOutput, errOutput)
+
+	var info, errs bytes.Buffer
+	infoOutput, errOutput = &info, &errs
+	SetVerbosity(VerbosityQuiet)
+	SetColorEnabled(true)
+
+	Success("hidden\n")
+	Warn("low on %s\n", "disk")
+	Errorf("failed to %s\n", "write")
+	if info.Len() != 0 {
+		t.Errorf("Expected no success message in quiet mode, got %q", info.String())
+	}
+	expected := ColorYellow + "Warning:" + ColorReset + " low on disk\n" +
+		ColorRed + "Error: failed to write" + ColorReset + "\n"
+	if errs.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, errs.String())
+	}
+
+	SetVerbosity(VerbosityNormal)
+	SetColorEnabled(false)
+	Success("created %s\n", "ABC-1")
+	if expected := "Success! created ABC-1\n"; info.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, info.String())
+	}
+}
@@ -0,0 +1,17 @@
+package util
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// HasHomePrefix reports whether path refers to the home directory with a
+// leading ~: "~" itself, "~/...", or "~\..." on Windows
+func HasHomePrefix(path string) bool {
+	return hasHomePrefix(path, filepath.Separator)
+}
+
+// hasHomePrefix is HasHomePrefix for an OS whose path separator is sep
+func hasHomePrefix(path string, sep rune) bool {
+	return path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(sep))
+}
@@ -0,0 +1,27 @@
+package util
+
+import "testing"
+
+// TestHasHomePrefix tests recognizing ~ paths with each OS's separator
+func TestHasHomePrefix(t *testing.T) {
+	testCases := []struct {
+		path     string
+		sep      rune
+		expected bool
+	}{
+		{"~", '/', true},
+		{"~/worktrees", '/', true},
+		{`~\worktrees`, '/', false},
+		{`~\worktrees`, '\\', true},
+		{"~/worktrees", '\\', true},
+		{"~alice/worktrees", '/', false},
+		{"/home/user/worktrees", '/', false},
+		{`C:\Users\user\worktrees`, '\\', false},
+	}
+
+	for _, tc := range testCases {
+		if result := hasHomePrefix(tc.path, tc.sep); result != tc.expected {
+			t.Errorf("hasHomePrefix(%q, %q): expected %v, got %v", tc.path, tc.sep, tc.expected, result)
+		}
+	}
+}
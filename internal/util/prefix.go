@@ -0,0 +1,42 @@
+package util
+
+import (
+	"bytes"
+	"io"
+)
+
+// PrefixWriter writes to an underlying writer with a prefix at the start of
+// every line, so output interleaved from several sources stays attributable
+type PrefixWriter struct {
+	w      io.Writer
+	prefix []byte
+	// lineStart is set when the next byte begins a line
+	lineStart bool
+}
+
+// NewPrefixWriter returns a writer that prefixes each line written to w
+func NewPrefixWriter(w io.Writer, prefix string) *PrefixWriter {
+	return &PrefixWriter{w: w, prefix: []byte(prefix), lineStart: true}
+}
+
+// Write writes p with the prefix before each line. A carriage return
+// starts a line as well, so redrawn progress lines keep their prefix. The
+// prefix is written with the first byte of a line, leaving empty lines
+// alone.
+func (pw *PrefixWriter) Write(p []byte) (int, error) {
+	var buf bytes.Buffer
+	for _, c := range p {
+		if pw.lineStart && c != '\n' && c != '\r' {
+			buf.Write(pw.prefix)
+			pw.lineStart = false
+		}
+		buf.WriteByte(c)
+		if c == '\n' || c == '\r' {
+			pw.lineStart = true
+		}
+	}
+	if _, err := pw.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// TestPrefixWriter tests that every line gets the prefix, however the
+// writes are split
+func TestPrefixWriter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		writes   []string
+		expected string
+	}{
+		{"single line", []string{"hello\n"}, "[ABC-1] hello\n"},
+		{"several lines", []string{"one\ntwo\n"}, "[ABC-1] one\n[ABC-1] two\n"},
+		{"split line", []string{"hel", "lo\nwor", "ld\n"}, "[ABC-1] hello\n[ABC-1] world\n"},
+		{"unterminated", []string{"done"}, "[ABC-1] done"},
+		{"empty line", []string{"one\n\ntwo\n"}, "[ABC-1] one\n\n[ABC-1] two\n"},
+		{"carriage return", []string{"\rFetching |", "\rFetching  \n"}, "\r[ABC-1] Fetching |\r[ABC-1] Fetching  \n"},
+		{"crlf", []string{"one\r\ntwo\r\n"}, "[ABC-1] one\r\n[ABC-1] two\r\n"},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		w := NewPrefixWriter(&buf, "[ABC-1] ")
+		for _, s := range tc.writes {
+			n, err := fmt.Fprint(w, s)
+			if err != nil || n != len(s) {
+				t.Fatalf("%s: expected %d bytes written, got %d, %v", tc.name, len(s), n, err)
+			}
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Select prints a numbered list of options to out and reads the user's
+// choice from in, returning the zero-based index of the selected option
+func Select(in io.Reader, out io.Writer, prompt string, options []string) (int, error) {
+	if len(options) == 0 {
+		return 0, errors.New("nothing to select")
+	}
+
+	for i, option := range options {
+		fmt.Fprintf(out, "  %s) %s\n", Colorize(strconv.Itoa(i+1), ColorCyan), option)
+	}
+	fmt.Fprintf(out, "%s [1-%d]: ", prompt, len(options))
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return 0, errors.New("no selection made")
+	}
+
+	choice, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || choice < 1 || choice > len(options) {
+		return 0, fmt.Errorf("invalid selection %q: enter a number from 1 to %d", strings.TrimSpace(line), len(options))
+	}
+	return choice - 1, nil
+}
+
+// Confirm prints a y/N question to out and reports whether the answer read
+// from in is yes. Anything other than "y" or "yes" counts as no.
+func Confirm(in io.Reader, out io.Writer, prompt string) bool {
+	fmt.Fprintf(out, "%s [y/N]: ", prompt)
+
+	line, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
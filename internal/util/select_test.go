@@ -0,0 +1,62 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestSelect tests choosing an option by number
+func TestSelect(t *testing.T) {
+	var out bytes.Buffer
+	index, err := Select(strings.NewReader("2\n"), &out, "Pick", []string{"ABC-1", "ABC-2"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if index != 1 {
+		t.Errorf("Expected index 1, got %d", index)
+	}
+	if !strings.Contains(out.String(), "ABC-1") || !strings.Contains(out.String(), "Pick [1-2]") {
+		t.Errorf("Expected options and prompt in output, got %q", out.String())
+	}
+}
+
+// TestSelectInvalid tests that out-of-range and non-numeric input is rejected
+func TestSelectInvalid(t *testing.T) {
+	for _, input := range []string{"0\n", "3\n", "abc\n", "\n", ""} {
+		var out bytes.Buffer
+		if _, err := Select(strings.NewReader(input), &out, "Pick", []string{"a", "b"}); err == nil {
+			t.Errorf("Expected error for input %q", input)
+		}
+	}
+
+	if _, err := Select(strings.NewReader("1\n"), &bytes.Buffer{}, "Pick", nil); err == nil {
+		t.Errorf("Expected error with no options")
+	}
+}
+
+// TestConfirm tests that only an explicit yes confirms
+func TestConfirm(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"yes", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+		{"sure\n", false},
+	}
+
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		if got := Confirm(strings.NewReader(tc.input), &out, "Remove?"); got != tc.expected {
+			t.Errorf("Confirm(%q) expected %v, got %v", tc.input, tc.expected, got)
+		}
+		if out.String() != "Remove? [y/N]: " {
+			t.Errorf("Expected prompt, got %q", out.String())
+		}
+	}
+}
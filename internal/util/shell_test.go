@@ -0,0 +1,85 @@
+package util
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// TestShellQuote tests quoting words with spaces and quotes
+func TestShellQuote(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"/home/user/worktrees/repo/ABC-746", "/home/user/worktrees/repo/ABC-746"},
+		{"/home/user/worktrees/My Repo/ABC-746", "'/home/user/worktrees/My Repo/ABC-746'"},
+		{"/tmp/it's", `'/tmp/it'\''s'`},
+		{"/tmp/$HOME", "'/tmp/$HOME'"},
+		{"", "''"},
+	}
+
+	for _, tc := range testCases {
+		if result := ShellQuote(tc.input); result != tc.expected {
+			t.Errorf("ShellQuote(%q): expected %s, got %s", tc.input, tc.expected, result)
+		}
+	}
+}
+
+// TestShellJoin tests quoting only the arguments that need it
+func TestShellJoin(t *testing.T) {
+	result := ShellJoin("git", "worktree", "add", "/tmp/My Repo/ABC-746", "-b", "ABC-746")
+	expected := "git worktree add '/tmp/My Repo/ABC-746' -b ABC-746"
+	if result != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
+	}
+}
+
+// TestShellQuoteRoundTrip tests that sh reads a quoted word back unchanged
+func TestShellQuoteRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("Skipping test: sh not installed")
+	}
+
+	for _, input := range []string{"My Repo/ABC 1", "it's", `a "b" $c`, "tab\there"} {
+		output, err := exec.Command("sh", "-c", "printf %s "+ShellQuote(input)).Output()
+		if err != nil {
+			t.Fatalf("sh failed for %q: %v", input, err)
+		}
+		if string(output) != input {
+			t.Errorf("Expected %q, got %q", input, output)
+		}
+	}
+}
+
+// TestShellSplit tests splitting command lines with quotes and escapes
+func TestShellSplit(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{"create {{.}} --track-remote", []string{"create", "{{.}}", "--track-remote"}},
+		{"  exec  {{.}}\t-- make test ", []string{"exec", "{{.}}", "--", "make", "test"}},
+		{`exec {{.}} -- sh -c 'echo "$PWD"'`, []string{"exec", "{{.}}", "--", "sh", "-c", `echo "$PWD"`}},
+		{`open "My Repo" it\'s "a \"b\" \c"`, []string{"open", "My Repo", "it's", `a "b" \c`}},
+		{`'' x""y`, []string{"", "xy"}},
+		{"", nil},
+	}
+
+	for _, tc := range testCases {
+		result, err := ShellSplit(tc.input)
+		if err != nil {
+			t.Errorf("ShellSplit(%q): unexpected error: %v", tc.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("ShellSplit(%q): expected %q, got %q", tc.input, tc.expected, result)
+		}
+	}
+
+	for _, input := range []string{"create 'ABC", `create "ABC`} {
+		if _, err := ShellSplit(input); err == nil {
+			t.Errorf("ShellSplit(%q): expected an error", input)
+		}
+	}
+}
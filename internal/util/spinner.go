@@ -0,0 +1,71 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are drawn in turn after the message; plain ASCII so every
+// console can show them
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// spinnerInterval is how long each frame is shown
+const spinnerInterval = 100 * time.Millisecond
+
+// Spinner shows a message with a small animation while a slow operation
+// runs. Without animation it prints the message once as a plain line, so
+// output that isn't a terminal stays readable.
+type Spinner struct {
+	w       io.Writer
+	message string
+	animate bool
+	stop    chan struct{}
+	done    sync.WaitGroup
+}
+
+// NewSpinner returns a spinner that writes message to w, animating it only
+// when animate is set
+func NewSpinner(w io.Writer, message string, animate bool) *Spinner {
+	return &Spinner{w: w, message: message, animate: animate}
+}
+
+// Start shows the message, animating it on a separate goroutine until Stop
+// is called
+func (s *Spinner) Start() {
+	if !s.animate {
+		fmt.Fprintln(s.w, s.message)
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			fmt.Fprintf(s.w, "\r%s %s", s.message, spinnerFrames[frame%len(spinnerFrames)])
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the animation, leaving the message on its own line as Start
+// prints it without animation
+func (s *Spinner) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.done.Wait()
+	s.stop = nil
+
+	// Redraw the message with a space over the last frame
+	fmt.Fprintf(s.w, "\r%s  \n", s.message)
+}
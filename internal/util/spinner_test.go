@@ -0,0 +1,39 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestSpinnerStatic tests that without animation the message is printed
+// once as a plain line
+func TestSpinnerStatic(t *testing.T) {
+	var buf bytes.Buffer
+	spinner := NewSpinner(&buf, "Fetching...", false)
+	spinner.Start()
+	spinner.Stop()
+
+	if buf.String() != "Fetching...\n" {
+		t.Errorf("Expected %q, got %q", "Fetching...\n", buf.String())
+	}
+}
+
+// TestSpinnerAnimated tests that the animation draws frames after the
+// message and leaves the plain message behind
+func TestSpinnerAnimated(t *testing.T) {
+	var buf bytes.Buffer
+	spinner := NewSpinner(&buf, "Fetching...", true)
+	spinner.Start()
+	spinner.Stop()
+	// A second Stop does nothing
+	spinner.Stop()
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "\rFetching... |") {
+		t.Errorf("Expected the first frame, got %q", output)
+	}
+	if !strings.HasSuffix(output, "\rFetching...  \n") || strings.Count(output, "\n") != 1 {
+		t.Errorf("Expected the frame cleared on one final line, got %q", output)
+	}
+}
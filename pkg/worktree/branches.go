@@ -0,0 +1,103 @@
+package worktree
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// defaultTicketPattern matches ticket IDs such as ABC-746. DanglingBranches
+// uses it when no ticket_pattern is configured, so branches like develop or
+// feature-login are never mistaken for tickets.
+var defaultTicketPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*-[0-9]+$`)
+
+// DanglingBranches returns the local branches named after a ticket whose
+// worktree directory no longer exists, such as those left behind by delete
+// without -d. Protected branches and branches checked out in any worktree
+// are never included.
+func (m *Manager) DanglingBranches() ([]string, error) {
+	pattern := defaultTicketPattern
+	if m.config.TicketPattern != "" {
+		re, err := regexp.Compile(m.config.TicketPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ticket_pattern %q: %w", m.config.TicketPattern, err)
+		}
+		pattern = re
+	}
+
+	branches, err := m.git.ListLocalBranches(m.ctx)
+	if err != nil {
+		return nil, err
+	}
+	worktreeMap, err := m.registeredWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	checkedOut := make(map[string]bool)
+	for _, branch := range worktreeMap {
+		checkedOut[branch] = true
+	}
+
+	var dangling []string
+	for _, branch := range branches {
+		ticket, ok := strings.CutPrefix(branch, m.config.BranchPrefix)
+		if !ok || validateTicket(ticket) != nil || !pattern.MatchString(ticket) {
+			continue
+		}
+		if m.config.IsProtected(branch) || checkedOut[branch] {
+			continue
+		}
+
+		path, err := m.ticketPath(ticket)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		dangling = append(dangling, branch)
+	}
+	return dangling, nil
+}
+
+// BranchResult records the outcome of deleting one branch in DeleteBranches
+type BranchResult struct {
+	Branch string
+	Err    error
+}
+
+// DeleteBranches deletes each of branches, refusing protected ones. A
+// failure for one branch does not stop the others; each outcome is
+// returned in order.
+func (m *Manager) DeleteBranches(branches []string) []BranchResult {
+	results := make([]BranchResult, 0, len(branches))
+	for _, branch := range branches {
+		var err error
+		if m.config.IsProtected(branch) {
+			err = fmt.Errorf("refusing to delete protected branch %s", branch)
+		} else if err = m.git.DeleteBranch(m.ctx, branch); err != nil {
+			err = fmt.Errorf("failed to delete branch: %w", err)
+		}
+		results = append(results, BranchResult{Branch: branch, Err: err})
+	}
+	return results
+}
+
+// RenderDanglingBranches writes the branches of repo that have no worktree
+func RenderDanglingBranches(w io.Writer, repo string, branches []string) {
+	if len(branches) == 0 {
+		fmt.Fprintf(w, "No branches without a worktree found for repository %s\n", util.Colorize(repo, util.ColorYellow))
+		return
+	}
+
+	fmt.Fprintf(w, "Branches without a worktree in repository %s:\n", util.Colorize(repo, util.ColorYellow))
+	for _, branch := range branches {
+		fmt.Fprintf(w, "  %s\n", util.Colorize(branch, util.ColorBlue))
+	}
+}
@@ -0,0 +1,89 @@
+package worktree
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// TestDanglingBranches tests which branches are reported as having no
+// worktree
+func TestDanglingBranches(t *testing.T) {
+	testCases := []struct {
+		name     string
+		prefix   string
+		pattern  string
+		branches []string
+		expected []string
+	}{
+		{"default pattern", "", "", []string{"ABC-2", "develop", "feature-login", "ABC-3"}, []string{"ABC-2"}},
+		{"branch prefix", "feature/", "", []string{"feature/ABC-2", "ABC-4", "feature/login"}, []string{"feature/ABC-2"}},
+		{"configured pattern", "", `^[a-z]+$`, []string{"ABC-2", "login"}, []string{"login"}},
+		{"protected branch", "", `^[a-z]+$`, []string{"master", "develop"}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newMockGit()
+			m := NewManagerWithGit(g, t.TempDir())
+			m.config.BranchPrefix = tc.prefix
+
+			// ABC-1 still has its worktree and ABC-3 is checked out elsewhere
+			if _, err := m.Create("ABC-1", CreateOptions{}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			m.config.TicketPattern = tc.pattern
+			g.worktrees = append(g.worktrees, GitWorktree{Path: "/elsewhere", Branch: "ABC-3"})
+			for _, branch := range tc.branches {
+				g.branches[branch] = true
+			}
+
+			dangling, err := m.DanglingBranches()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if strings.Join(dangling, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Expected %v, got %v", tc.expected, dangling)
+			}
+		})
+	}
+}
+
+// TestDeleteBranches tests that each branch is deleted except protected ones
+func TestDeleteBranches(t *testing.T) {
+	g := newMockGit()
+	g.branches["ABC-2"] = true
+	m := NewManagerWithGit(g, t.TempDir())
+
+	results := m.DeleteBranches([]string{"ABC-2", "main"})
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Branch != "ABC-2" || results[0].Err != nil {
+		t.Errorf("Expected ABC-2 to be deleted, got %+v", results[0])
+	}
+	if results[1].Branch != "main" || results[1].Err == nil {
+		t.Errorf("Expected protected branch main to be refused, got %+v", results[1])
+	}
+	assertCalls(t, g, "delete-branch ABC-2")
+}
+
+// TestRenderDanglingBranches tests the listing and the empty message
+func TestRenderDanglingBranches(t *testing.T) {
+	defer util.SetColorEnabled(util.ColorEnabled())
+	util.SetColorEnabled(false)
+
+	var buf bytes.Buffer
+	RenderDanglingBranches(&buf, "repo", []string{"ABC-2"})
+	if buf.String() != "Branches without a worktree in repository repo:\n  ABC-2\n" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+
+	buf.Reset()
+	RenderDanglingBranches(&buf, "repo", nil)
+	if !strings.Contains(buf.String(), "No branches without a worktree") {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+}
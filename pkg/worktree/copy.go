@@ -0,0 +1,216 @@
+package worktree
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// copyPaths copies the paths under srcRoot, relative to it, into the same
+// location under dstRoot. It returns the paths that were copied.
+func copyPaths(srcRoot, dstRoot string, paths []string) ([]string, error) {
+	var copied []string
+	for _, rel := range paths {
+		if err := copyPath(filepath.Join(srcRoot, rel), filepath.Join(dstRoot, rel)); err != nil {
+			return copied, fmt.Errorf("failed to copy %s: %w", rel, err)
+		}
+		copied = append(copied, rel)
+	}
+	return copied, nil
+}
+
+// ignoredPaths narrows the matched paths under srcRoot to the ones git
+// ignores, asking ignoredBy about every path under them in one go. An
+// ignored directory is kept whole; one that isn't is searched for ignored
+// files and directories inside it. The files left out are returned as
+// skipped.
+func ignoredPaths(srcRoot string, matches []string, ignoredBy func(paths []string) (map[string]bool, error)) (ignored, skipped []string, err error) {
+	type candidate struct {
+		path, rel string
+		dir       bool
+	}
+	var candidates []candidate
+	for _, match := range matches {
+		err := filepath.WalkDir(filepath.Join(srcRoot, match), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			rel, err := filepath.Rel(srcRoot, path)
+			if err != nil {
+				return err
+			}
+			candidates = append(candidates, candidate{path: path, rel: rel, dir: d.IsDir()})
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	paths := make([]string, len(candidates))
+	for i, c := range candidates {
+		paths[i] = c.path
+	}
+	isIgnored, err := ignoredBy(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// WalkDir lists a directory's contents right after it, so everything
+	// under a directory that is kept whole follows it
+	keptDir := ""
+	for _, c := range candidates {
+		if keptDir != "" && strings.HasPrefix(c.rel, keptDir+string(filepath.Separator)) {
+			continue
+		}
+		switch {
+		case isIgnored[c.path]:
+			ignored = append(ignored, c.rel)
+			if c.dir {
+				keptDir = c.rel
+			}
+		case !c.dir:
+			skipped = append(skipped, c.rel)
+		}
+	}
+	return ignored, skipped, nil
+}
+
+// matchPatterns returns the paths under srcRoot, relative to it, that match
+// the glob patterns
+func matchPatterns(srcRoot string, patterns []string) ([]string, error) {
+	var matched []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(srcRoot, pattern))
+		if err != nil {
+			return matched, fmt.Errorf("invalid copy pattern %q: %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			rel, err := filepath.Rel(srcRoot, match)
+			if err != nil {
+				return matched, err
+			}
+			matched = append(matched, rel)
+		}
+	}
+	return matched, nil
+}
+
+// copyPath copies a file, or a directory recursively, preserving permissions
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+// copyFile copies a single regular file, creating parent directories
+func copyFile(src, dst string, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	// OpenFile's mode is filtered by the umask, so set it explicitly
+	return os.Chmod(dst, perm)
+}
+
+// copyTemplate copies the contents of templateDir into dstRoot without
+// overwriting anything: a path that already exists in dstRoot is left as
+// it is and reported as a conflict instead. A .git entry at the top of the
+// template is skipped, so a template kept in a repository of its own can be
+// used as it is. It returns the relative paths of the files copied and of
+// the conflicts.
+func copyTemplate(templateDir, dstRoot string) (copied, conflicts []string, err error) {
+	err = filepath.WalkDir(templateDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(templateDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if rel == ".git" {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dstRoot, rel)
+		existing, statErr := os.Lstat(target)
+
+		if d.IsDir() {
+			if statErr == nil && !existing.IsDir() {
+				conflicts = append(conflicts, rel)
+				return fs.SkipDir
+			}
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if statErr == nil {
+			conflicts = append(conflicts, rel)
+			return nil
+		}
+		if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", rel, err)
+		}
+		copied = append(copied, rel)
+		return nil
+	})
+	return copied, conflicts, err
+}
@@ -0,0 +1,97 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestCopyPaths tests copying the files matched by patterns and preserving
+// permissions
+func TestCopyPaths(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+
+	files := map[string]os.FileMode{
+		".env":                   0600,
+		"config/app.local.yaml":  0644,
+		"config/app.shared.yaml": 0644,
+		"bin/setup.sh":           0755,
+	}
+	for name, perm := range files {
+		path := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), perm); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	matches, err := matchPatterns(src, []string{".env", "config/*.local.yaml", "bin", ".envrc"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	copied, err := copyPaths(src, dst, matches)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{".env", filepath.Join("config", "app.local.yaml"), "bin"}
+	if !reflect.DeepEqual(copied, expected) {
+		t.Errorf("Expected copied %v, got %v", expected, copied)
+	}
+
+	for _, name := range []string{".env", "config/app.local.yaml", "bin/setup.sh"} {
+		info, err := os.Stat(filepath.Join(dst, name))
+		if err != nil {
+			t.Errorf("Expected %s to be copied: %v", name, err)
+			continue
+		}
+		if info.Mode().Perm() != files[name] {
+			t.Errorf("Expected %s mode %v, got %v", name, files[name], info.Mode().Perm())
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dst, "config/app.shared.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Expected app.shared.yaml not to be copied")
+	}
+}
+
+// TestCopyTemplate tests that a template fills in missing files without
+// touching existing ones, and skips its own .git
+func TestCopyTemplate(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	for _, name := range []string{"README.md", "scripts/dev.sh", "docs", ".git/HEAD", ".vscode/settings.json"} {
+		writeFile(t, filepath.Join(src, name), "template "+name)
+	}
+	if err := os.Chmod(filepath.Join(src, "scripts/dev.sh"), 0755); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+	writeFile(t, filepath.Join(dst, "README.md"), "checked out")
+	// A file where the template has a directory is a conflict too
+	writeFile(t, filepath.Join(dst, ".vscode"), "checked out")
+
+	copied, conflicts, err := copyTemplate(src, dst)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"docs", filepath.Join("scripts", "dev.sh")}; !reflect.DeepEqual(copied, expected) {
+		t.Errorf("Expected copied %v, got %v", expected, copied)
+	}
+	if expected := []string{".vscode", "README.md"}; !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("Expected conflicts %v, got %v", expected, conflicts)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dst, "README.md")); string(data) != "checked out" {
+		t.Errorf("Expected README.md to be kept, got %q", data)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "scripts/dev.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("Expected scripts/dev.sh to be copied with its mode, got %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, ".git")); !os.IsNotExist(err) {
+		t.Errorf("Expected the template's .git not to be copied")
+	}
+}
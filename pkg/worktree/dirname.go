@@ -0,0 +1,173 @@
+package worktree
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mdelgado509/go-worktree/internal/config"
+)
+
+// now returns the current time, replaced in tests
+var now = time.Now
+
+// dateLayout formats the Date field of a dir_template
+const dateLayout = "2006-01-02"
+
+// datePattern matches a Date field rendered with dateLayout
+var datePattern = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+
+// dirTemplateData holds the fields a dir_template can use
+type dirTemplateData struct {
+	// Ticket is the ticket ID
+	Ticket string
+	// Branch is the worktree's branch, with slashes replaced by dashes
+	Branch string
+	// Date is the day the worktree is created, as YYYY-MM-DD
+	Date string
+}
+
+// dirPart is a piece of a rendered dir_template: either literal text or
+// the name of a field
+type dirPart struct {
+	text  string
+	field bool
+}
+
+// dirTemplate names worktree directories from the dir_template setting
+// and recovers the ticket from the names it rendered
+type dirTemplate struct {
+	text  string
+	tmpl  *template.Template
+	parts []dirPart
+}
+
+// parseDirTemplate parses a dir_template, which has to use {{.Ticket}} so
+// that directories can be matched back to their tickets
+func parseDirTemplate(text string) (*dirTemplate, error) {
+	tmpl, err := template.New("dir_template").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dir_template %q: %w", text, err)
+	}
+
+	// Render each field as its name between NUL bytes, which can't occur
+	// in the template's own text, to find where the fields end up
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, dirTemplateData{Ticket: "\x00Ticket\x00", Branch: "\x00Branch\x00", Date: "\x00Date\x00"}); err != nil {
+		return nil, fmt.Errorf("invalid dir_template %q: %w", text, err)
+	}
+	if !strings.Contains(buf.String(), "\x00Ticket\x00") {
+		return nil, fmt.Errorf("invalid dir_template %q: must include {{.Ticket}}", text)
+	}
+
+	var parts []dirPart
+	for i, piece := range strings.Split(buf.String(), "\x00") {
+		if piece != "" || i%2 == 1 {
+			parts = append(parts, dirPart{text: piece, field: i%2 == 1})
+		}
+	}
+	return &dirTemplate{text: text, tmpl: tmpl, parts: parts}, nil
+}
+
+// render returns the directory name for a new worktree
+func (d *dirTemplate) render(ticket, branch string, date time.Time) (string, error) {
+	var buf strings.Builder
+	data := dirTemplateData{
+		Ticket: ticket,
+		Branch: branchDirName(branch),
+		Date:   date.Format(dateLayout),
+	}
+	if err := d.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render dir_template %q: %w", d.text, err)
+	}
+	if err := validateTicket(buf.String()); err != nil {
+		return "", fmt.Errorf("dir_template %q gives an invalid directory name for ticket %s: %w", d.text, ticket, err)
+	}
+	return buf.String(), nil
+}
+
+// ticket recovers the ticket from a directory name. A name can often be
+// split into fields several ways, as with "{{.Ticket}}-{{.Branch}}" and
+// ticket IDs containing dashes, so a split whose branch is the one
+// branchFor derives from the ticket is preferred. Names the template
+// doesn't match, such as directories created before it was configured,
+// are taken as the ticket itself.
+func (d *dirTemplate) ticket(name string, branchFor func(ticket string) string) string {
+	var first, preferred string
+	d.match(name, d.parts, map[string]string{}, func(fields map[string]string) bool {
+		if first == "" {
+			first = fields["Ticket"]
+		}
+		if branch, ok := fields["Branch"]; ok && branch != branchDirName(branchFor(fields["Ticket"])) {
+			return false
+		}
+		preferred = fields["Ticket"]
+		return true
+	})
+
+	switch {
+	case preferred != "":
+		return preferred
+	case first != "":
+		return first
+	}
+	return name
+}
+
+// match tries every way of splitting name into parts, shortest fields
+// first, calling accept with the fields of each split until it returns
+// true
+func (d *dirTemplate) match(name string, parts []dirPart, fields map[string]string, accept func(map[string]string) bool) bool {
+	if len(parts) == 0 {
+		return name == "" && accept(fields)
+	}
+
+	part := parts[0]
+	if !part.field {
+		rest, ok := strings.CutPrefix(name, part.text)
+		return ok && d.match(rest, parts[1:], fields, accept)
+	}
+	if value, ok := fields[part.text]; ok {
+		// A field used twice has the same value both times
+		rest, ok := strings.CutPrefix(name, value)
+		return ok && d.match(rest, parts[1:], fields, accept)
+	}
+
+	for n := 1; n <= len(name); n++ {
+		value := name[:n]
+		if part.text == "Date" && !datePattern.MatchString(value) {
+			continue
+		}
+		fields[part.text] = value
+		if d.match(name[n:], parts[1:], fields, accept) {
+			return true
+		}
+		delete(fields, part.text)
+	}
+	return false
+}
+
+// branchDirName makes a branch usable in a directory name
+func branchDirName(branch string) string {
+	return strings.NewReplacer("/", "-", `\`, "-").Replace(branch)
+}
+
+// dirTemplate returns the configured dir_template, or nil when directories
+// are simply named after their ticket
+func (m *Manager) dirTemplate() (*dirTemplate, error) {
+	text := m.config.DirTemplate
+	if text == "" || text == config.DefaultDirTemplate {
+		return nil, nil
+	}
+	return parseDirTemplate(text)
+}
+
+// dirTicket returns the ticket of the worktree directory called name
+func (m *Manager) dirTicket(tmpl *dirTemplate, name string) string {
+	if tmpl == nil {
+		return name
+	}
+	return tmpl.ticket(name, func(ticket string) string { return m.branchName(ticket, "") })
+}
@@ -0,0 +1,146 @@
+package worktree
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestParseDirTemplate tests that templates must be valid and use the ticket
+func TestParseDirTemplate(t *testing.T) {
+	testCases := []struct {
+		text  string
+		valid bool
+	}{
+		{"{{.Ticket}}", true},
+		{"{{.Date}}-{{.Ticket}}", true},
+		{"{{.Ticket}}-{{.Branch}}", true},
+		{"{{.Ticket", false},
+		{"{{.Branch}}", false},
+		{"{{.Nope}}-{{.Ticket}}", false},
+	}
+
+	for _, tc := range testCases {
+		_, err := parseDirTemplate(tc.text)
+		if (err == nil) != tc.valid {
+			t.Errorf("%q: expected valid %v, got error %v", tc.text, tc.valid, err)
+		}
+	}
+}
+
+// TestDirTemplateRender tests rendering directory names
+func TestDirTemplateRender(t *testing.T) {
+	date := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		text     string
+		branch   string
+		expected string
+	}{
+		{"{{.Ticket}}", "ABC-746", "ABC-746"},
+		{"{{.Date}}-{{.Ticket}}", "ABC-746", "2024-03-07-ABC-746"},
+		{"{{.Ticket}}_{{.Branch}}", "feature/login", "ABC-746_feature-login"},
+	}
+
+	for _, tc := range testCases {
+		tmpl, err := parseDirTemplate(tc.text)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		name, err := tmpl.render("ABC-746", tc.branch, date)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if name != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.text, tc.expected, name)
+		}
+	}
+
+	tmpl, err := parseDirTemplate("{{.Ticket}}/x")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tmpl.render("ABC-746", "ABC-746", date); err == nil {
+		t.Errorf("Expected an error for a name with a path separator")
+	}
+}
+
+// TestDirTemplateTicket tests recovering tickets from directory names
+func TestDirTemplateTicket(t *testing.T) {
+	branchFor := func(ticket string) string { return "feature/" + ticket }
+	testCases := []struct {
+		text     string
+		name     string
+		expected string
+	}{
+		{"{{.Date}}-{{.Ticket}}", "2024-03-07-ABC-746", "ABC-746"},
+		{"{{.Ticket}}-{{.Date}}", "ABC-746-2024-03-07", "ABC-746"},
+		// The branch derived from the ticket tells where the ticket ends
+		{"{{.Ticket}}-{{.Branch}}", "ABC-746-feature-ABC-746", "ABC-746"},
+		// A custom branch can't be checked, so the shortest ticket is used
+		{"{{.Ticket}}_{{.Branch}}", "ABC-746_login", "ABC-746"},
+		{"wt-{{.Ticket}}-{{.Ticket}}", "wt-ABC-1-ABC-1", "ABC-1"},
+		// Directories from before the template was set keep their name
+		{"{{.Date}}-{{.Ticket}}", "ABC-746", "ABC-746"},
+	}
+
+	for _, tc := range testCases {
+		tmpl, err := parseDirTemplate(tc.text)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if ticket := tmpl.ticket(tc.name, branchFor); ticket != tc.expected {
+			t.Errorf("%q with %q: expected %q, got %q", tc.text, tc.name, tc.expected, ticket)
+		}
+	}
+}
+
+// TestCreateDirTemplate tests that create, list, cd, rename, and delete
+// agree on templated directory names
+func TestCreateDirTemplate(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC) }
+
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	m.config.DirTemplate = "{{.Date}}-{{.Ticket}}"
+	repoPath := filepath.Join(m.basePath, "test-repo")
+
+	result, err := m.Create("ABC-746", CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := filepath.Join(repoPath, "2024-03-07-ABC-746"); result.Path != expected {
+		t.Errorf("Expected path %s, got %s", expected, result.Path)
+	}
+
+	// A later day still finds the same directory
+	now = func() time.Time { return time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC) }
+	if path, err := m.ExistingPath("746"); err != nil || path != result.Path {
+		t.Errorf("Expected %s, got %s (%v)", result.Path, path, err)
+	}
+	entries, err := m.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Ticket != "ABC-746" || entries[0].Path != result.Path {
+		t.Errorf("Expected the ABC-746 entry, got %+v", entries)
+	}
+	if _, err := m.Create("ABC-746", CreateOptions{}); err == nil {
+		t.Errorf("Expected an error creating ABC-746 twice")
+	}
+
+	if err := m.Rename("ABC-746", "ABC-747"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	renamed := filepath.Join(repoPath, "2024-03-08-ABC-747")
+	if path, err := m.ExistingPath("ABC-747"); err != nil || path != renamed {
+		t.Errorf("Expected %s, got %s (%v)", renamed, path, err)
+	}
+
+	if err := m.Delete("ABC-747", DeleteOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tickets, _ := m.Tickets(); len(tickets) != 0 {
+		t.Errorf("Expected no worktrees left, got %v", tickets)
+	}
+}
@@ -0,0 +1,65 @@
+package worktree
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mdelgado509/go-worktree/internal/git"
+)
+
+// Errors returned by Manager methods, matched with errors.Is
+var (
+	// ErrNotARepo means the current directory is not inside a git repository
+	ErrNotARepo = git.ErrNotARepo
+	// ErrGitFailed means a git command exited unsuccessfully
+	ErrGitFailed = git.ErrCommandFailed
+	// ErrBranchNotMerged means git refused to delete a branch with
+	// unmerged commits
+	ErrBranchNotMerged = git.ErrBranchNotMerged
+	// ErrBranchInUse means git refused to delete a branch checked out in a
+	// worktree
+	ErrBranchInUse = git.ErrBranchInUse
+	// ErrWorktreeLocked means git refused to remove a locked worktree
+	ErrWorktreeLocked = git.ErrWorktreeLocked
+	// ErrWorktreeExists means the worktree directory is already present
+	ErrWorktreeExists = errors.New("worktree already exists")
+	// ErrWorktreeNotFound means no worktree exists for the ticket
+	ErrWorktreeNotFound = errors.New("worktree not found")
+	// ErrWorktreeDirty means a worktree has uncommitted changes, or git
+	// refused to remove it because of them
+	ErrWorktreeDirty = git.ErrWorktreeDirty
+	// ErrBranchCheckedOut means a branch is already checked out in another
+	// worktree
+	ErrBranchCheckedOut = errors.New("branch already checked out")
+	// ErrBranchNotFound means a required branch does not exist
+	ErrBranchNotFound = git.ErrBranchNotFound
+	// ErrInvalidTicket means a ticket ID was rejected
+	ErrInvalidTicket = errors.New("invalid ticket ID")
+	// ErrNotInWorktree means "@" was used outside a managed worktree
+	ErrNotInWorktree = errors.New("not inside a managed worktree")
+	// ErrAmbiguousTicket means a partial ticket ID matched several worktrees
+	ErrAmbiguousTicket = errors.New("ambiguous ticket ID")
+)
+
+// kindError carries a descriptive message while matching a sentinel error
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// errorf formats an error like fmt.Errorf that also matches kind
+func errorf(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
@@ -0,0 +1,34 @@
+package worktree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Exec runs args as a command in the worktree for ticket, connected to the
+// standard streams. The ticket may be "@" or a partial ID, as with GetPath.
+// When the command runs but exits unsuccessfully the *exec.ExitError is
+// returned as it is, so callers can pass its exit code on.
+func (m *Manager) Exec(ticket string, args []string) error {
+	if len(args) == 0 {
+		return errors.New("no command given")
+	}
+	path, err := m.ExistingPath(ticket)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = path
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	return err
+}
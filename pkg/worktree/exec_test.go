@@ -0,0 +1,43 @@
+package worktree
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestExec tests running a command in a worktree found by a partial ticket
+// and passing on its exit status
+func TestExec(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("Skipping test: sh not installed")
+	}
+
+	m := NewManagerWithGit(newMockGit(), t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+
+	if err := m.Exec("746", []string{"sh", "-c", "echo ok > out.txt"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "out.txt")); err != nil {
+		t.Errorf("Expected the command to run in %s: %v", path, err)
+	}
+
+	err := m.Exec("ABC-746", []string{"sh", "-c", "exit 3"})
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Expected exit status 3, got %v", err)
+	}
+
+	if err := m.Exec("XYZ-1", []string{"true"}); !errors.Is(err, ErrWorktreeNotFound) {
+		t.Errorf("Expected ErrWorktreeNotFound, got %v", err)
+	}
+	if err := m.Exec("ABC-746", nil); err == nil {
+		t.Errorf("Expected an error without a command")
+	}
+}
@@ -0,0 +1,170 @@
+package worktree
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mdelgado509/go-worktree/internal/config"
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// maxRepoDepth is how many directory levels a repository name spans at
+// most under the base path, as with org/repo for repo_key remote
+const maxRepoDepth = 2
+
+// ListGlobal returns the worktrees of every repository under the base
+// path, sorted by repository and then as opts.Sort asks. Unlike List it
+// needs no repository: each worktree's branch and lock are read from the
+// git metadata its .git file points to, and a worktree git no longer knows
+// about gets the branch "unknown". As with List, worktrees that can't be
+// read are listed with Err set and reported in a *ListError.
+func (m *Manager) ListGlobal(opts ListOptions) ([]Entry, error) {
+	if err := validateSort(opts.Sort); err != nil {
+		return nil, err
+	}
+	tmpl, err := m.dirTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	indexes := map[string]worktreeIndex{}
+	err = filepath.WalkDir(m.basePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == m.basePath && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.IsDir() || path == m.basePath {
+			return nil
+		}
+
+		rel, err := filepath.Rel(m.basePath, path)
+		if err != nil {
+			return err
+		}
+		depth := strings.Count(rel, string(filepath.Separator)) + 1
+		if depth == 1 {
+			return nil
+		}
+
+		if _, err := os.Lstat(filepath.Join(path, ".git")); err != nil {
+			// Not a worktree, so either part of a repository name or a
+			// stale directory that is not worth walking
+			if depth > maxRepoDepth {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		entry := Entry{
+			Repo:   filepath.ToSlash(filepath.Dir(rel)),
+			Ticket: m.dirTicket(tmpl, d.Name()),
+			Path:   path,
+			Branch: "unknown",
+			Err:    checkReadable(path),
+		}
+		readGitDir(&entry)
+		// The index is only read here; List keeps it up to date
+		repoPath := filepath.Dir(path)
+		index, ok := indexes[repoPath]
+		if !ok {
+			if indexPath, err := config.IndexPath(repoPath); err == nil {
+				index, _ = readIndex(indexPath)
+			}
+			indexes[repoPath] = index
+		}
+		if recorded, ok := index.Worktrees[entry.Ticket]; ok {
+			fillFromIndex(&entry, recorded)
+		}
+		entries = append(entries, entry)
+		return fs.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worktree directory: %w", err)
+	}
+
+	if opts.Size || opts.Sort == SortSize {
+		fillSizes(entries)
+	}
+	// Sort within each repository, keeping repositories together
+	sortEntries(entries, opts.Sort, opts.Reverse)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Repo < entries[j].Repo })
+	return entries, listError(entries)
+}
+
+// readGitDir fills in the branch and lock of entry from the administrative
+// directory its .git file points to, leaving them alone when the worktree
+// is no longer registered
+func readGitDir(entry *Entry) {
+	gitDir, ok := linkedGitDir(entry.Path)
+	if !ok {
+		gitDir = filepath.Join(entry.Path, ".git")
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return
+	}
+	entry.Branch = "detached"
+	if ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: "); ok {
+		entry.Branch = strings.TrimPrefix(ref, "refs/heads/")
+	}
+
+	if reason, err := os.ReadFile(filepath.Join(gitDir, "locked")); err == nil {
+		entry.Locked = true
+		entry.LockReason = strings.TrimSpace(string(reason))
+	}
+}
+
+// linkedGitDir returns the administrative directory that the .git file of
+// the linked worktree at path points to. It reports false when path has no
+// such file, as in a main worktree, whose .git is a directory, or a plain
+// directory.
+func linkedGitDir(path string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(path, ".git"))
+	if err != nil {
+		return "", false
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", false
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return dir, true
+}
+
+// RenderGlobalList writes the listing from ListGlobal, with a section per
+// repository laid out like RenderList
+func RenderGlobalList(w io.Writer, basePath string, entries []Entry, opts RenderListOptions) {
+	if len(entries) == 0 {
+		if _, err := os.Stat(basePath); errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(w, "No worktrees yet, the base path %s will be created by the first create\n",
+				util.Colorize(basePath, util.ColorBlue))
+			return
+		}
+		fmt.Fprintf(w, "No worktrees found in %s\n", util.Colorize(basePath, util.ColorBlue))
+		return
+	}
+
+	for start := 0; start < len(entries); {
+		end := start + 1
+		for end < len(entries) && entries[end].Repo == entries[start].Repo {
+			end++
+		}
+		if start > 0 && !opts.NoHeader {
+			fmt.Fprintln(w)
+		}
+		RenderList(w, entries[start].Repo, basePath, entries[start:end], opts)
+		start = end
+	}
+}
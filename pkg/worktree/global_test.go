@@ -0,0 +1,104 @@
+package worktree
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFile creates path with content, making its parent directories
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// TestListGlobal tests reading worktrees of several repositories straight
+// from the base path
+func TestListGlobal(t *testing.T) {
+	basePath := t.TempDir()
+	gitDirs := t.TempDir()
+
+	// addWorktree creates a worktree directory whose .git file points at an
+	// administrative directory with the given HEAD
+	addWorktree := func(rel, head string) string {
+		path := filepath.Join(basePath, filepath.FromSlash(rel))
+		gitDir := filepath.Join(gitDirs, strings.ReplaceAll(rel, "/", "-"))
+		writeFile(t, filepath.Join(path, ".git"), "gitdir: "+gitDir+"\n")
+		if head != "" {
+			writeFile(t, filepath.Join(gitDir, "HEAD"), head+"\n")
+		}
+		return gitDir
+	}
+
+	addWorktree("web/ABC-1", "ref: refs/heads/feature/ABC-1")
+	lockedDir := addWorktree("web/ABC-2", "ref: refs/heads/ABC-2")
+	writeFile(t, filepath.Join(lockedDir, "locked"), "on a USB drive\n")
+	addWorktree("api/XY-9", "1111111111111111111111111111111111111111")
+	addWorktree("acme/app/APP-3", "ref: refs/heads/APP-3")
+	// Unregistered worktrees and stale directories
+	addWorktree("api/GONE-1", "")
+	writeFile(t, filepath.Join(basePath, "web", "STALE-1", "src", "deep", "file.txt"), "")
+
+	m := NewManagerWithGit(newMockGit(), basePath)
+	entries, err := m.ListGlobal(ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Entry{
+		{Repo: "acme/app", Ticket: "APP-3", Path: filepath.Join(basePath, "acme", "app", "APP-3"), Branch: "APP-3"},
+		{Repo: "api", Ticket: "GONE-1", Path: filepath.Join(basePath, "api", "GONE-1"), Branch: "unknown"},
+		{Repo: "api", Ticket: "XY-9", Path: filepath.Join(basePath, "api", "XY-9"), Branch: "detached"},
+		{Repo: "web", Ticket: "ABC-1", Path: filepath.Join(basePath, "web", "ABC-1"), Branch: "feature/ABC-1"},
+		{Repo: "web", Ticket: "ABC-2", Path: filepath.Join(basePath, "web", "ABC-2"), Branch: "ABC-2",
+			Locked: true, LockReason: "on a USB drive"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entries)
+	}
+}
+
+// TestListGlobalMissingBasePath tests that a base path that was never
+// created lists nothing
+func TestListGlobalMissingBasePath(t *testing.T) {
+	m := NewManagerWithGit(newMockGit(), filepath.Join(t.TempDir(), "missing"))
+	entries, err := m.ListGlobal(ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no entries, got %+v", entries)
+	}
+}
+
+// TestRenderGlobalList tests that the listing has a section per repository
+func TestRenderGlobalList(t *testing.T) {
+	var buf bytes.Buffer
+	RenderGlobalList(&buf, "/tmp/wt", []Entry{
+		{Repo: "api", Ticket: "XY-9", Path: "/tmp/wt/api/XY-9", Branch: "XY-9"},
+		{Repo: "web", Ticket: "ABC-1", Path: "/tmp/wt/web/ABC-1", Branch: "ABC-1"},
+		{Repo: "web", Ticket: "ABC-2", Path: "/tmp/wt/web/ABC-2", Branch: "ABC-2"},
+	}, RenderListOptions{})
+
+	output := buf.String()
+	if strings.Count(output, "Worktrees for repository") != 2 {
+		t.Errorf("Expected a heading per repository, got %q", output)
+	}
+	if !strings.Contains(output, "/tmp/wt/api/XY-9\n\nWorktrees for repository") {
+		t.Errorf("Expected a blank line between repositories, got %q", output)
+	}
+
+	buf.Reset()
+	RenderGlobalList(&buf, t.TempDir(), nil, RenderListOptions{})
+	if !strings.HasPrefix(buf.String(), "No worktrees found in") {
+		t.Errorf("Expected the empty message, got %q", buf.String())
+	}
+}
@@ -0,0 +1,21 @@
+package worktree
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// runHook runs a shell command with dir as its working directory, streaming
+// its output to stdout and stderr
+func runHook(command, dir string, stdout, stderr io.Writer) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("post-create hook %q failed: %w", command, err)
+	}
+	return nil
+}
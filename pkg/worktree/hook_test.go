@@ -0,0 +1,42 @@
+package worktree
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunHook tests that the hook runs in the worktree and streams output
+func TestRunHook(t *testing.T) {
+	dir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	err := runHook("echo out; echo err >&2; touch created", dir, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.TrimSpace(stdout.String()) != "out" {
+		t.Errorf("Expected stdout %q, got %q", "out", stdout.String())
+	}
+	if strings.TrimSpace(stderr.String()) != "err" {
+		t.Errorf("Expected stderr %q, got %q", "err", stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "created")); err != nil {
+		t.Errorf("Expected hook to run in %s: %v", dir, err)
+	}
+}
+
+// TestRunHookFailure tests that a non-zero exit is reported
+func TestRunHookFailure(t *testing.T) {
+	var out bytes.Buffer
+	err := runHook("exit 3", t.TempDir(), &out, &out)
+	if err == nil {
+		t.Fatalf("Expected error for failing hook")
+	}
+	if !strings.Contains(err.Error(), "exit 3") {
+		t.Errorf("Expected error to name the hook, got %v", err)
+	}
+}
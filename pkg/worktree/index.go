@@ -0,0 +1,181 @@
+package worktree
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mdelgado509/go-worktree/internal/config"
+)
+
+// IndexEntry is what the index records about one worktree: when and from
+// what base it was created, which git itself doesn't remember. Each
+// repository's index is kept in the state directory, at the path given by
+// config.IndexPath.
+type IndexEntry struct {
+	Branch string `json:"branch"`
+	// Base is the ref the branch started at; empty when an existing branch
+	// was checked out or the worktree was found without being created here
+	Base      string    `json:"base,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// worktreeIndex is the content of the index file
+type worktreeIndex struct {
+	// Worktrees maps tickets to what is recorded about their worktrees
+	Worktrees map[string]IndexEntry `json:"worktrees"`
+}
+
+// indexPath returns the index file for the current repository, or "" when
+// the repository has no worktree directory yet and so nothing to index
+func (m *Manager) indexPath() (string, error) {
+	repo, err := m.repoName()
+	if err != nil {
+		return "", err
+	}
+	repoDir := filepath.Join(m.basePath, repo)
+	if _, err := os.Stat(repoDir); errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	return config.IndexPath(repoDir)
+}
+
+// readIndex reads the index file at path. A missing file gives an empty
+// index.
+func readIndex(path string) (worktreeIndex, error) {
+	index := worktreeIndex{Worktrees: map[string]IndexEntry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return index, fmt.Errorf("failed to read worktree index: %w", err)
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return worktreeIndex{Worktrees: map[string]IndexEntry{}}, fmt.Errorf("failed to parse worktree index %s: %w", path, err)
+	}
+	if index.Worktrees == nil {
+		index.Worktrees = map[string]IndexEntry{}
+	}
+	return index, nil
+}
+
+// writeIndex replaces the index file at path, through a temporary file so
+// an interrupted write can't leave it truncated
+func writeIndex(path string, index worktreeIndex) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode worktree index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write worktree index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write worktree index: %w", err)
+	}
+	return nil
+}
+
+// updateIndex applies change to the current repository's index and writes
+// it back. The index only adds detail to listings, so a failure is a
+// warning rather than failing the operation that triggered it, and an
+// unreadable index is started over to be filled in again by List. Nothing
+// is written in dry-run mode.
+func (m *Manager) updateIndex(change func(worktrees map[string]IndexEntry)) {
+	if m.dryRun != nil {
+		return
+	}
+	path, err := m.indexPath()
+	if err != nil {
+		m.warnf("failed to update worktree index: %v\n", err)
+		return
+	}
+	if path == "" {
+		return
+	}
+
+	index, _ := readIndex(path)
+	change(index.Worktrees)
+	if err := writeIndex(path, index); err != nil {
+		m.warnf("%v\n", err)
+	}
+}
+
+// applyIndex fills in the base and creation time of the managed entries
+// from the index, reconciling it with them first: worktrees missing from
+// the index are added with the time their .git file was written as the
+// creation time, branches that changed are updated, and tickets whose
+// worktree is gone are dropped. The index is only rewritten when it
+// changed.
+func (m *Manager) applyIndex(entries []Entry) {
+	path, err := m.indexPath()
+	if err != nil || path == "" {
+		return
+	}
+
+	index, err := readIndex(path)
+	changed := err != nil
+	present := make(map[string]bool, len(entries))
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Unmanaged {
+			continue
+		}
+		present[entry.Ticket] = true
+
+		recorded, ok := index.Worktrees[entry.Ticket]
+		if !ok {
+			recorded = IndexEntry{Branch: entry.Branch, CreatedAt: createdTime(entry.Path)}
+			changed = true
+		}
+		if recorded.Branch != entry.Branch && entry.Branch != "detached" {
+			recorded.Branch = entry.Branch
+			changed = true
+		}
+		index.Worktrees[entry.Ticket] = recorded
+		fillFromIndex(entry, recorded)
+	}
+	for ticket := range index.Worktrees {
+		if !present[ticket] {
+			delete(index.Worktrees, ticket)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := writeIndex(path, index); err != nil {
+			m.warnf("%v\n", err)
+		}
+	}
+}
+
+// fillFromIndex copies what the index records into entry
+func fillFromIndex(entry *Entry, recorded IndexEntry) {
+	entry.Base = recorded.Base
+	if !recorded.CreatedAt.IsZero() {
+		createdAt := recorded.CreatedAt
+		entry.CreatedAt = &createdAt
+	}
+}
+
+// createdTime estimates when the worktree at path was created for
+// worktrees the index doesn't know about. Git writes the worktree's .git
+// file once when adding it, so its modification time is a good guess; the
+// directory's own is the fallback.
+func createdTime(path string) time.Time {
+	for _, candidate := range []string{filepath.Join(path, ".git"), path} {
+		if info, err := os.Stat(candidate); err == nil {
+			return info.ModTime()
+		}
+	}
+	return time.Time{}
+}
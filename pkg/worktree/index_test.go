@@ -0,0 +1,142 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mdelgado509/go-worktree/internal/config"
+)
+
+// indexFile returns the index file of the test repository under the
+// manager's base path
+func indexFile(t *testing.T, m *Manager) string {
+	t.Helper()
+	path, err := config.IndexPath(filepath.Join(m.basePath, "test-repo"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return path
+}
+
+// TestIndexLifecycle tests that Create, Rename, and Delete keep the index
+// up to date
+func TestIndexLifecycle(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return created }
+
+	m := NewManagerWithGit(newMockGit(), t.TempDir())
+	path := indexFile(t, m)
+	assertIndex := func(expected map[string]IndexEntry) {
+		t.Helper()
+		index, err := readIndex(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(index.Worktrees, expected) {
+			t.Errorf("Expected index %+v, got %+v", expected, index.Worktrees)
+		}
+	}
+
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertIndex(map[string]IndexEntry{"ABC-746": {Branch: "ABC-746", Base: "main", CreatedAt: created}})
+
+	if err := m.Rename("ABC-746", "ABC-747"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertIndex(map[string]IndexEntry{"ABC-747": {Branch: "ABC-747", Base: "main", CreatedAt: created}})
+
+	if err := m.Delete("ABC-747", DeleteOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertIndex(map[string]IndexEntry{})
+}
+
+// TestIndexReconcile tests that List repairs an index that has drifted
+// from the worktrees on disk, or can't be read at all
+func TestIndexReconcile(t *testing.T) {
+	m := NewManagerWithGit(newMockGit(), t.TempDir())
+	for _, ticket := range []string{"ABC-1", "ABC-2"} {
+		if _, err := m.Create(ticket, CreateOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	path := indexFile(t, m)
+	// Without a .git file the directory's time stands in for the creation
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, ticket := range []string{"ABC-1", "ABC-2"} {
+		if err := os.Chtimes(filepath.Join(m.basePath, "test-repo", ticket), modified, modified); err != nil {
+			t.Fatalf("Failed to set mtime: %v", err)
+		}
+	}
+	recorded := time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		index    string
+		expected map[string]IndexEntry
+	}{
+		{
+			"drifted",
+			`{"worktrees": {
+				"ABC-1": {"branch": "old-name", "base": "origin/develop", "created_at": "2023-05-06T07:08:09Z"},
+				"GONE-1": {"branch": "GONE-1", "created_at": "2023-05-06T07:08:09Z"}
+			}}`,
+			map[string]IndexEntry{
+				"ABC-1": {Branch: "ABC-1", Base: "origin/develop", CreatedAt: recorded},
+				"ABC-2": {Branch: "ABC-2", CreatedAt: modified},
+			},
+		},
+		{
+			"corrupt",
+			`{"worktrees": [`,
+			map[string]IndexEntry{
+				"ABC-1": {Branch: "ABC-1", CreatedAt: modified},
+				"ABC-2": {Branch: "ABC-2", CreatedAt: modified},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		writeFile(t, path, tc.index)
+
+		entries, err := m.List(ListOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, entry := range entries {
+			expected := tc.expected[entry.Ticket]
+			if entry.Base != expected.Base || entry.CreatedAt == nil || !entry.CreatedAt.Equal(expected.CreatedAt) {
+				t.Errorf("%s: expected %s to have base %q and creation time %v, got %q and %v",
+					tc.name, entry.Ticket, expected.Base, expected.CreatedAt, entry.Base, entry.CreatedAt)
+			}
+		}
+
+		index, err := readIndex(path)
+		if err != nil {
+			t.Fatalf("%s: expected the index to be rewritten: %v", tc.name, err)
+		}
+		for ticket, entry := range index.Worktrees {
+			index.Worktrees[ticket] = IndexEntry{Branch: entry.Branch, Base: entry.Base, CreatedAt: entry.CreatedAt.UTC()}
+		}
+		if !reflect.DeepEqual(index.Worktrees, tc.expected) {
+			t.Errorf("%s: expected index %+v, got %+v", tc.name, tc.expected, index.Worktrees)
+		}
+	}
+}
+
+// TestReadIndexMissing tests that a missing index reads as an empty one
+func TestReadIndexMissing(t *testing.T) {
+	index, err := readIndex(filepath.Join(t.TempDir(), "index.json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if index.Worktrees == nil || len(index.Worktrees) != 0 {
+		t.Errorf("Expected an empty index, got %+v", index.Worktrees)
+	}
+}
@@ -0,0 +1,557 @@
+package worktree
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// Entry describes a single managed worktree
+type Entry struct {
+	// Repo is the repository the worktree belongs to, only filled in by
+	// ListGlobal
+	Repo   string `json:"repo,omitempty"`
+	Ticket string `json:"ticket"`
+	Path   string `json:"path"`
+	Branch string `json:"branch"`
+	// Size is the on-disk size in bytes, only filled in when requested
+	Size int64 `json:"size,omitempty"`
+	// Unmanaged marks a git worktree outside the managed directory, only
+	// listed with ListOptions.All
+	Unmanaged bool `json:"unmanaged,omitempty"`
+	// Main marks the repository's main checkout, as opposed to a linked
+	// worktree
+	Main bool `json:"main,omitempty"`
+	// Locked marks a worktree locked with git worktree lock, LockReason
+	// holds the reason given, if any
+	Locked     bool   `json:"locked,omitempty"`
+	LockReason string `json:"lock_reason,omitempty"`
+	// Merged marks a worktree whose branch is fully merged into the default
+	// base branch, only filled in with ListOptions.Stale
+	Merged bool `json:"merged,omitempty"`
+	// Base is the ref the worktree's branch started at and CreatedAt when
+	// the worktree was created, both taken from the index and empty when
+	// unknown
+	Base      string     `json:"base,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	// Err is why the worktree couldn't be read fully, encoded as "error";
+	// the other fields hold what could be read
+	Err error `json:"-"`
+}
+
+// MarshalJSON encodes the entry with Err as an "error" string field
+func (e Entry) MarshalJSON() ([]byte, error) {
+	type entry Entry
+	encoded := struct {
+		entry
+		Error string `json:"error,omitempty"`
+	}{entry: entry(e)}
+	if e.Err != nil {
+		encoded.Error = e.Err.Error()
+	}
+	return json.Marshal(encoded)
+}
+
+// ListError is returned by List and ListGlobal along with the listing when
+// some worktrees couldn't be read. Those entries are still listed, with
+// their Err set.
+type ListError struct {
+	// Entries are the entries with Err set
+	Entries []Entry
+}
+
+func (e *ListError) Error() string {
+	var failures []string
+	for _, entry := range e.Entries {
+		failures = append(failures, fmt.Sprintf("%s: %v", entry.Ticket, entry.Err))
+	}
+	return fmt.Sprintf("failed to read %d worktree%s (%s)",
+		len(e.Entries), plural(len(e.Entries), "", "s"), strings.Join(failures, "; "))
+}
+
+// Unwrap returns the error of each entry, so errors.Is can look for causes
+// such as fs.ErrPermission
+func (e *ListError) Unwrap() []error {
+	errs := make([]error, len(e.Entries))
+	for i, entry := range e.Entries {
+		errs[i] = entry.Err
+	}
+	return errs
+}
+
+// listError returns a *ListError for the entries that have Err set, or nil
+// when every entry was read
+func listError(entries []Entry) error {
+	var failed []Entry
+	for _, entry := range entries {
+		if entry.Err != nil {
+			failed = append(failed, entry)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &ListError{Entries: failed}
+}
+
+// ListOptions controls what List gathers for each worktree
+type ListOptions struct {
+	// Size computes the on-disk size of each worktree, which can be slow
+	Size bool
+	// All also includes worktrees registered with git outside the managed
+	// directory, such as the main checkout
+	All bool
+	// Sort orders the listing by one of SortKeys; empty keeps the managed
+	// worktrees in ticket order followed by any unmanaged ones
+	Sort string
+	// Reverse reverses the order given by Sort
+	Reverse bool
+	// Stale marks the managed worktrees whose branch is fully merged into
+	// the default base branch
+	Stale bool
+}
+
+// Keys the listing can be sorted by. Names and branches sort
+// alphabetically, modification times newest first, and sizes largest
+// first.
+const (
+	SortName   = "name"
+	SortBranch = "branch"
+	SortMtime  = "mtime"
+	SortSize   = "size"
+)
+
+// SortKeys lists the accepted values of ListOptions.Sort
+var SortKeys = []string{SortName, SortBranch, SortMtime, SortSize}
+
+// List returns the managed worktrees for the current repository. A
+// worktree that can't be read doesn't stop the listing: it is listed with
+// Err set, and a *ListError is returned along with the entries.
+func (m *Manager) List(opts ListOptions) ([]Entry, error) {
+	if err := validateSort(opts.Sort); err != nil {
+		return nil, err
+	}
+
+	entries, err := m.entries()
+	if err != nil {
+		return nil, err
+	}
+	m.applyIndex(entries)
+	if opts.Stale {
+		if err := m.markMerged(entries); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.All {
+		unmanaged, err := m.unmanagedEntries(entries)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, unmanaged...)
+	}
+
+	if opts.Size || opts.Sort == SortSize {
+		fillSizes(entries)
+	}
+	sortEntries(entries, opts.Sort, opts.Reverse)
+	return entries, listError(entries)
+}
+
+// markMerged sets Merged on the entries whose branch is fully merged into
+// the default base branch, as it is on the remote when fetched. The base
+// branch itself and detached worktrees are never marked.
+func (m *Manager) markMerged(entries []Entry) error {
+	remote := m.config.RemoteName("")
+	baseBranch := m.baseBranch("", remote)
+	base, err := m.baseRef(remote, baseBranch)
+	if err != nil {
+		return err
+	}
+	merged, err := m.git.MergedBranches(m.ctx, base)
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		branch := entries[i].Branch
+		if branch != baseBranch && branch != "detached" && slices.Contains(merged, branch) {
+			entries[i].Merged = true
+		}
+	}
+	return nil
+}
+
+// fillSizes computes the size of each entry, recording a failure in the
+// entry's Err
+func fillSizes(entries []Entry) {
+	for i := range entries {
+		size, err := dirSize(entries[i].Path)
+		if err != nil {
+			setErr(&entries[i], err)
+			continue
+		}
+		entries[i].Size = size
+	}
+}
+
+// setErr records err on entry unless it already has an error, which is
+// likely the cause of this one
+func setErr(entry *Entry, err error) {
+	if entry.Err == nil {
+		entry.Err = err
+	}
+}
+
+// checkReadable returns an error when the worktree directory at path can't
+// be listed, so that an unreadable worktree is reported rather than shown
+// as if nothing were wrong
+func checkReadable(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	if _, err := dir.Readdirnames(1); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// validateSort rejects sort keys other than SortKeys
+func validateSort(key string) error {
+	if key != "" && !slices.Contains(SortKeys, key) {
+		return fmt.Errorf("invalid sort %q: expected one of %s", key, strings.Join(SortKeys, ", "))
+	}
+	return nil
+}
+
+// sortEntries orders entries by key, keeping the current order for equal
+// keys. Sorting by SortSize expects the sizes to be filled in already, and
+// sorting by SortMtime reads modification times as Recent does; an entry
+// whose time can't be read gets Err set and sorts as the oldest.
+func sortEntries(entries []Entry, key string, reverse bool) {
+	var less func(a, b int) bool
+	switch key {
+	case "":
+		return
+	case SortName:
+		less = func(a, b int) bool { return entries[a].Ticket < entries[b].Ticket }
+	case SortBranch:
+		less = func(a, b int) bool { return entries[a].Branch < entries[b].Branch }
+	case SortSize:
+		less = func(a, b int) bool { return entries[a].Size > entries[b].Size }
+	case SortMtime:
+		modified := make(map[string]time.Time, len(entries))
+		for i := range entries {
+			t, err := modTime(entries[i].Path)
+			if err != nil {
+				setErr(&entries[i], err)
+			}
+			modified[entries[i].Path] = t
+		}
+		less = func(a, b int) bool { return modified[entries[a].Path].After(modified[entries[b].Path]) }
+	}
+
+	if reverse {
+		sort.SliceStable(entries, func(a, b int) bool { return less(b, a) })
+	} else {
+		sort.SliceStable(entries, less)
+	}
+}
+
+// entries gathers the managed worktrees for the current repository
+func (m *Manager) entries() ([]Entry, error) {
+	dirs, err := m.worktreeDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees, err := m.git.ListWorktrees(m.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	worktreeMap := make(map[string]GitWorktree)
+	for _, wt := range worktrees {
+		worktreeMap[wt.Path] = wt
+	}
+
+	entries := []Entry{}
+	for _, dir := range dirs {
+		wt, exists := lookupPath(worktreeMap, dir.path)
+		branch := wt.Branch
+		if !exists || wt.Detached {
+			branch = "detached"
+		}
+
+		entries = append(entries, Entry{
+			Ticket:     dir.ticket,
+			Path:       dir.path,
+			Branch:     branch,
+			Locked:     wt.Locked,
+			LockReason: wt.LockReason,
+			Err:        checkReadable(dir.path),
+		})
+	}
+
+	// Directory names only follow ticket order with the default
+	// dir_template
+	sortEntries(entries, SortName, false)
+	return entries, nil
+}
+
+// unmanagedEntries returns the git worktrees that are not among the
+// managed entries. The ticket of an unmanaged entry is its directory name.
+func (m *Manager) unmanagedEntries(managed []Entry) ([]Entry, error) {
+	worktrees, err := m.git.ListWorktrees(m.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	// git reports resolved paths, so match against both forms
+	managedPaths := make(map[string]bool)
+	for _, entry := range managed {
+		managedPaths[entry.Path] = true
+		if resolved, err := filepath.EvalSymlinks(entry.Path); err == nil {
+			managedPaths[resolved] = true
+		}
+	}
+
+	var entries []Entry
+	for i, wt := range worktrees {
+		if wt.Bare || managedPaths[wt.Path] {
+			continue
+		}
+
+		branch := wt.Branch
+		if wt.Detached {
+			branch = "detached"
+		}
+		entries = append(entries, Entry{
+			Ticket:    filepath.Base(wt.Path),
+			Path:      wt.Path,
+			Branch:    branch,
+			Unmanaged: true,
+			// git lists the main worktree first
+			Main:       i == 0,
+			Locked:     wt.Locked,
+			LockReason: wt.LockReason,
+		})
+	}
+	return entries, nil
+}
+
+// Tickets returns the tickets of the worktree directories for the repo
+func (m *Manager) Tickets() ([]string, error) {
+	dirs, err := m.worktreeDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []string
+	for _, dir := range dirs {
+		tickets = append(tickets, dir.ticket)
+	}
+	return tickets, nil
+}
+
+// worktreeDir is a directory under the repo's worktree root and the ticket
+// it belongs to
+type worktreeDir struct {
+	ticket string
+	path   string
+}
+
+// worktreeDirs returns the worktree directories for the repo, with their
+// tickets recovered through the dir_template
+func (m *Manager) worktreeDirs() ([]worktreeDir, error) {
+	repo, err := m.repoName()
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := m.dirTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	repoPath := filepath.Join(m.basePath, repo)
+	dirEntries, err := os.ReadDir(repoPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worktree directory: %w", err)
+	}
+
+	var dirs []worktreeDir
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			dirs = append(dirs, worktreeDir{
+				ticket: m.dirTicket(tmpl, dirEntry.Name()),
+				path:   filepath.Join(repoPath, dirEntry.Name()),
+			})
+		}
+	}
+	return dirs, nil
+}
+
+// RenderListOptions controls how RenderList lays out the listing
+type RenderListOptions struct {
+	// Size adds a column with each worktree's on-disk size
+	Size bool
+	// NoHeader leaves out the title line and the column headings, leaving
+	// one line per worktree
+	NoHeader bool
+	// Details adds columns with each worktree's base and creation time
+	Details bool
+}
+
+// createdLayout formats the creation time in the detailed listing
+const createdLayout = "2006-01-02 15:04"
+
+// RenderList writes the human-readable, colorized listing for repo, whose
+// worktrees are kept under basePath, as aligned columns. An empty listing
+// says whether the base path has not been created yet or the repo has no
+// worktrees.
+func RenderList(w io.Writer, repo, basePath string, entries []Entry, opts RenderListOptions) {
+	if len(entries) == 0 {
+		if _, err := os.Stat(basePath); errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(w, "No worktrees yet, the base path %s will be created by the first create\n",
+				util.Colorize(basePath, util.ColorBlue))
+			return
+		}
+		fmt.Fprintf(w, "No worktrees found for repository %s in %s\n",
+			util.Colorize(repo, util.ColorYellow), util.Colorize(filepath.Join(basePath, repo), util.ColorBlue))
+		return
+	}
+
+	// tabwriter counts color codes toward a cell's width, so every cell in a
+	// column, heading included, is colorized alike. All the color codes are
+	// the same length, which keeps the padding right with colors on or off.
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !opts.NoHeader {
+		fmt.Fprintf(w, "Worktrees for repository %s in %s:\n",
+			util.Colorize(repo, util.ColorYellow), util.Colorize(filepath.Join(basePath, repo), util.ColorBlue))
+		headings := []string{util.Colorize("TICKET", util.ColorWhite), util.Colorize("BRANCH", util.ColorWhite)}
+		if opts.Details {
+			headings = append(headings, util.Colorize("BASE", util.ColorWhite), "CREATED")
+		}
+		headings = append(headings, "PATH")
+		if opts.Size {
+			headings = append(headings, util.Colorize("SIZE", util.ColorWhite))
+		}
+		fmt.Fprintf(tw, "  %s\n", strings.Join(headings, "\t"))
+	}
+
+	for _, entry := range entries {
+		ticketColor, marker := util.ColorGreen, ""
+		switch {
+		case entry.Main:
+			ticketColor, marker = util.ColorPurple, " "+util.Colorize("[main]", util.ColorPurple)
+		case entry.Unmanaged:
+			marker = " " + util.Colorize("[unmanaged]", util.ColorYellow)
+		}
+		if entry.Locked {
+			marker += " " + util.Colorize("[locked]", util.ColorRed)
+		}
+		if entry.Merged {
+			marker += " " + util.Colorize("(merged)", util.ColorCyan)
+		}
+		if entry.Err != nil {
+			marker += " " + util.Colorize("(error: "+entryErrorText(entry.Err)+")", util.ColorRed)
+		}
+
+		cells := []string{
+			util.Colorize(entry.Ticket, ticketColor),
+			util.Colorize(entry.Branch, util.ColorBlue),
+		}
+		if opts.Details {
+			base, created := "-", "-"
+			if entry.Base != "" {
+				base = entry.Base
+			}
+			if entry.CreatedAt != nil {
+				created = entry.CreatedAt.Local().Format(createdLayout)
+			}
+			cells = append(cells, util.Colorize(base, util.ColorBlue), created)
+		}
+		cells = append(cells, entry.Path)
+		if opts.Size {
+			cells = append(cells, util.Colorize(formatBytes(entry.Size), util.ColorCyan))
+		}
+		fmt.Fprintf(tw, "  %s%s\n", strings.Join(cells, "\t"), marker)
+	}
+	tw.Flush()
+}
+
+// entryErrorText shortens an entry's error for the listing, where the path
+// is already shown: an error about a file leaves out the operation and path
+func entryErrorText(err error) string {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// RenderJSON writes the listing as a JSON array without color codes
+func RenderJSON(w io.Writer, entries []Entry) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(entries); err != nil {
+		return fmt.Errorf("failed to encode worktrees: %w", err)
+	}
+	return nil
+}
+
+// RenderPorcelain writes the listing in a stable, line-oriented format for
+// scripts, like git worktree list --porcelain: each worktree is a record of
+// "key value" lines starting with ticket, path, and branch and ending with
+// a blank line. Optional keys follow the branch, and the main, unmanaged,
+// locked, and merged keys may stand alone as labels.
+func RenderPorcelain(w io.Writer, entries []Entry) {
+	for _, entry := range entries {
+		fmt.Fprintf(w, "ticket %s\n", entry.Ticket)
+		fmt.Fprintf(w, "path %s\n", entry.Path)
+		fmt.Fprintf(w, "branch %s\n", entry.Branch)
+		if entry.Repo != "" {
+			fmt.Fprintf(w, "repo %s\n", entry.Repo)
+		}
+		if entry.Size != 0 {
+			fmt.Fprintf(w, "size %d\n", entry.Size)
+		}
+		if entry.Base != "" {
+			fmt.Fprintf(w, "base %s\n", entry.Base)
+		}
+		if entry.CreatedAt != nil {
+			fmt.Fprintf(w, "created %s\n", entry.CreatedAt.Format(time.RFC3339))
+		}
+		if entry.Main {
+			fmt.Fprintln(w, "main")
+		}
+		if entry.Unmanaged {
+			fmt.Fprintln(w, "unmanaged")
+		}
+		if entry.Locked {
+			fmt.Fprintln(w, strings.TrimSpace("locked "+entry.LockReason))
+		}
+		if entry.Merged {
+			fmt.Fprintln(w, "merged")
+		}
+		if entry.Err != nil {
+			fmt.Fprintf(w, "error %s\n", entry.Err)
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,290 @@
+package worktree
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// TestRenderJSON tests that JSON output contains the expected fields
+func TestRenderJSON(t *testing.T) {
+	entries := []Entry{
+		{Ticket: "ABC-746", Path: "/tmp/wt/repo/ABC-746", Branch: "ABC-746"},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderJSON(&buf, entries); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("JSON output must not contain color codes: %q", buf.String())
+	}
+
+	var decoded []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0]["ticket"] != "ABC-746" ||
+		decoded[0]["path"] != "/tmp/wt/repo/ABC-746" || decoded[0]["branch"] != "ABC-746" {
+		t.Errorf("Unexpected JSON: %v", decoded)
+	}
+}
+
+// TestRenderJSONEmpty tests that no worktrees renders an empty array
+func TestRenderJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderJSON(&buf, []Entry{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("Expected [], got %q", buf.String())
+	}
+}
+
+// TestRenderPorcelain tests the line-oriented listing for scripts
+func TestRenderPorcelain(t *testing.T) {
+	var buf bytes.Buffer
+	RenderPorcelain(&buf, []Entry{
+		{Ticket: "ABC-746", Path: "/tmp/wt/repo/ABC-746", Branch: "ABC-746", Size: 2048},
+		{Ticket: "repo", Path: "/src/repo", Branch: "main", Unmanaged: true, Main: true},
+		{Ticket: "ABC-747", Path: "/tmp/wt/repo/ABC-747", Branch: "detached", Locked: true, LockReason: "on usb drive"},
+		{Ticket: "ABC-748", Path: "/tmp/wt/repo/ABC-748", Branch: "feature/ABC-748", Locked: true, Merged: true},
+	})
+
+	expected := "ticket ABC-746\npath /tmp/wt/repo/ABC-746\nbranch ABC-746\nsize 2048\n\n" +
+		"ticket repo\npath /src/repo\nbranch main\nmain\nunmanaged\n\n" +
+		"ticket ABC-747\npath /tmp/wt/repo/ABC-747\nbranch detached\nlocked on usb drive\n\n" +
+		"ticket ABC-748\npath /tmp/wt/repo/ABC-748\nbranch feature/ABC-748\nlocked\nmerged\n\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+// TestRenderPorcelainIndex tests the base and creation time lines
+func TestRenderPorcelainIndex(t *testing.T) {
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	RenderPorcelain(&buf, []Entry{
+		{Ticket: "ABC-746", Path: "/tmp/wt/repo/ABC-746", Branch: "ABC-746", Base: "origin/main", CreatedAt: &created},
+	})
+
+	expected := "ticket ABC-746\npath /tmp/wt/repo/ABC-746\nbranch ABC-746\nbase origin/main\ncreated 2024-03-07T12:00:00Z\n\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+// TestRenderPorcelainEmpty tests that no worktrees renders nothing
+func TestRenderPorcelainEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	RenderPorcelain(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output, got %q", buf.String())
+	}
+}
+
+// TestRenderList tests the human-readable listing
+func TestRenderList(t *testing.T) {
+	var buf bytes.Buffer
+	RenderList(&buf, "repo", "/tmp/wt", []Entry{
+		{Ticket: "ABC-746", Path: "/tmp/wt/repo/ABC-746", Branch: "feature/ABC-746", Size: 2048},
+	}, RenderListOptions{Size: true})
+
+	output := buf.String()
+	for _, want := range []string{"repo in /tmp/wt/repo:", "ABC-746", "/tmp/wt/repo/ABC-746", "feature/ABC-746", "2.0 KB"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, output)
+		}
+	}
+}
+
+// TestRenderListLocked tests the marker on locked worktrees
+func TestRenderListLocked(t *testing.T) {
+	defer util.SetColorEnabled(util.ColorEnabled())
+	util.SetColorEnabled(false)
+
+	var buf bytes.Buffer
+	RenderList(&buf, "repo", "/tmp/wt", []Entry{
+		{Ticket: "ABC-746", Path: "/tmp/wt/repo/ABC-746", Branch: "ABC-746", Locked: true},
+		{Ticket: "ABC-747", Path: "/tmp/wt/repo/ABC-747", Branch: "ABC-747"},
+	}, RenderListOptions{NoHeader: true})
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "/tmp/wt/repo/ABC-746 [locked]") ||
+		strings.Contains(lines[1], "[locked]") {
+		t.Errorf("Expected only ABC-746 to be marked locked, got %q", buf.String())
+	}
+}
+
+// TestRenderListMerged tests the tag on worktrees marked merged
+func TestRenderListMerged(t *testing.T) {
+	defer util.SetColorEnabled(util.ColorEnabled())
+	util.SetColorEnabled(false)
+
+	var buf bytes.Buffer
+	RenderList(&buf, "repo", "/tmp/wt", []Entry{
+		{Ticket: "ABC-746", Path: "/tmp/wt/repo/ABC-746", Branch: "ABC-746", Merged: true},
+		{Ticket: "ABC-747", Path: "/tmp/wt/repo/ABC-747", Branch: "ABC-747"},
+	}, RenderListOptions{NoHeader: true})
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "/tmp/wt/repo/ABC-746 (merged)") ||
+		strings.Contains(lines[1], "(merged)") {
+		t.Errorf("Expected only ABC-746 to be marked merged, got %q", buf.String())
+	}
+}
+
+// TestRenderListColumns tests that columns line up once color codes are
+// removed, and that --no-header leaves only the rows
+func TestRenderListColumns(t *testing.T) {
+	defer util.SetColorEnabled(util.ColorEnabled())
+	entries := []Entry{
+		{Ticket: "ABC-1", Path: "/tmp/wt/repo/ABC-1", Branch: "feature/ABC-1", Size: 2048},
+		{Ticket: "LONGPROJECT-1234", Path: "/tmp/wt/repo/LONGPROJECT-1234", Branch: "LONGPROJECT-1234", Size: 3 << 20},
+		{Ticket: "repo", Path: "/src/repo", Branch: "main", Unmanaged: true, Main: true},
+	}
+
+	for _, color := range []bool{false, true} {
+		util.SetColorEnabled(color)
+		var buf bytes.Buffer
+		RenderList(&buf, "repo", "/tmp/wt", entries, RenderListOptions{Size: true})
+
+		lines := strings.Split(strings.TrimSuffix(util.StripColors(buf.String()), "\n"), "\n")
+		expected := []string{
+			"Worktrees for repository repo in /tmp/wt/repo:",
+			"  TICKET            BRANCH            PATH                           SIZE",
+			"  ABC-1             feature/ABC-1     /tmp/wt/repo/ABC-1             2.0 KB",
+			"  LONGPROJECT-1234  LONGPROJECT-1234  /tmp/wt/repo/LONGPROJECT-1234  3.0 MB",
+			"  repo              main              /src/repo                      0 B [main]",
+		}
+		if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("Color %v: expected\n%s\ngot\n%s", color, strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+		}
+	}
+
+	util.SetColorEnabled(false)
+	var buf bytes.Buffer
+	RenderList(&buf, "repo", "/tmp/wt", entries[:1], RenderListOptions{NoHeader: true})
+	if buf.String() != "  ABC-1  feature/ABC-1  /tmp/wt/repo/ABC-1\n" {
+		t.Errorf("Unexpected output without header %q", buf.String())
+	}
+}
+
+// TestRenderListDetails tests the base and creation time columns, with a
+// dash for what the index doesn't know
+func TestRenderListDetails(t *testing.T) {
+	defer util.SetColorEnabled(util.ColorEnabled())
+	util.SetColorEnabled(false)
+
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.Local)
+	var buf bytes.Buffer
+	RenderList(&buf, "repo", "/tmp/wt", []Entry{
+		{Ticket: "ABC-1", Path: "/tmp/wt/repo/ABC-1", Branch: "ABC-1", Base: "origin/main", CreatedAt: &created},
+		{Ticket: "repo", Path: "/src/repo", Branch: "main", Unmanaged: true, Main: true},
+	}, RenderListOptions{Details: true})
+
+	expected := "Worktrees for repository repo in /tmp/wt/repo:\n" +
+		"  TICKET  BRANCH  BASE         CREATED           PATH\n" +
+		"  ABC-1   ABC-1   origin/main  2024-03-07 12:00  /tmp/wt/repo/ABC-1\n" +
+		"  repo    main    -            -                 /src/repo [main]\n"
+	if buf.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+// TestRenderListError tests that an entry that couldn't be read shows its
+// error inline, in every format
+func TestRenderListError(t *testing.T) {
+	defer util.SetColorEnabled(util.ColorEnabled())
+	util.SetColorEnabled(false)
+
+	entries := []Entry{
+		{Ticket: "ABC-746", Path: "/tmp/wt/repo/ABC-746", Branch: "detached",
+			Err: &fs.PathError{Op: "open", Path: "/tmp/wt/repo/ABC-746", Err: fs.ErrPermission}},
+	}
+
+	var buf bytes.Buffer
+	RenderList(&buf, "repo", "/tmp/wt", entries, RenderListOptions{NoHeader: true})
+	if expected := "  ABC-746  detached  /tmp/wt/repo/ABC-746 (error: permission denied)\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	RenderPorcelain(&buf, entries)
+	if !strings.Contains(buf.String(), "\nerror open /tmp/wt/repo/ABC-746: permission denied\n") {
+		t.Errorf("Expected an error line, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := RenderJSON(&buf, entries); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"error": "open /tmp/wt/repo/ABC-746: permission denied"`) {
+		t.Errorf("Expected an error field, got %q", buf.String())
+	}
+
+	err := listError(append(entries, Entry{Ticket: "ABC-747"}))
+	if expected := "failed to read 1 worktree (ABC-746: open /tmp/wt/repo/ABC-746: permission denied)"; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+	if err := listError(entries[:0]); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+// TestRenderListMain tests that the main checkout is marked instead of
+// being shown as just another unmanaged worktree
+func TestRenderListMain(t *testing.T) {
+	defer util.SetColorEnabled(util.ColorEnabled())
+	util.SetColorEnabled(false)
+
+	var buf bytes.Buffer
+	RenderList(&buf, "repo", "/tmp/wt", []Entry{
+		{Ticket: "repo", Path: "/src/repo", Branch: "main", Unmanaged: true, Main: true},
+		{Ticket: "experiment", Path: "/tmp/experiment", Branch: "detached", Unmanaged: true},
+	}, RenderListOptions{})
+
+	output := buf.String()
+	for _, want := range []string{"  repo        main      /src/repo [main]\n", "  experiment  detached  /tmp/experiment [unmanaged]\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, output)
+		}
+	}
+}
+
+// TestRenderListEmpty tests telling a missing base path apart from a
+// repository without worktrees
+func TestRenderListEmpty(t *testing.T) {
+	defer util.SetColorEnabled(util.ColorEnabled())
+	util.SetColorEnabled(false)
+
+	basePath := filepath.Join(t.TempDir(), "worktrees")
+	testCases := []struct {
+		create   bool
+		expected string
+	}{
+		{false, "No worktrees yet, the base path " + basePath + " will be created by the first create\n"},
+		{true, "No worktrees found for repository repo in " + filepath.Join(basePath, "repo") + "\n"},
+	}
+
+	for _, tc := range testCases {
+		if tc.create {
+			if err := os.Mkdir(basePath, 0755); err != nil {
+				t.Fatalf("Failed to create base path: %v", err)
+			}
+		}
+		var buf bytes.Buffer
+		RenderList(&buf, "repo", basePath, nil, RenderListOptions{})
+		if buf.String() != tc.expected {
+			t.Errorf("Expected %q, got %q", tc.expected, buf.String())
+		}
+	}
+}
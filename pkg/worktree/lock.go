@@ -0,0 +1,36 @@
+package worktree
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Lock locks the worktree for ticket so git worktree prune leaves it
+// alone, which helps for worktrees on removable media. The reason is
+// recorded with the lock when it is not empty.
+func (m *Manager) Lock(ticket, reason string) error {
+	path, err := m.ExistingPath(ticket)
+	if err != nil {
+		return err
+	}
+
+	if err := m.git.LockWorktree(m.ctx, path, reason); err != nil {
+		return fmt.Errorf("failed to lock worktree: %w", err)
+	}
+	m.successf("Worktree for ticket %s has been locked\n", filepath.Base(path))
+	return nil
+}
+
+// Unlock removes the lock from the worktree for ticket
+func (m *Manager) Unlock(ticket string) error {
+	path, err := m.ExistingPath(ticket)
+	if err != nil {
+		return err
+	}
+
+	if err := m.git.UnlockWorktree(m.ctx, path); err != nil {
+		return fmt.Errorf("failed to unlock worktree: %w", err)
+	}
+	m.successf("Worktree for ticket %s has been unlocked\n", filepath.Base(path))
+	return nil
+}
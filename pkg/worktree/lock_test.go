@@ -0,0 +1,71 @@
+package worktree
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLock tests locking and unlocking a worktree and that the lock shows
+// up in List
+func TestLock(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	g.calls = nil
+
+	if err := m.Lock("746", "on a USB drive"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	entries, err := m.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 1 || !entries[0].Locked || entries[0].LockReason != "on a USB drive" {
+		t.Errorf("Expected a locked entry, got %+v", entries)
+	}
+
+	if err := m.Lock("ABC-746", ""); err == nil {
+		t.Errorf("Expected an error locking a locked worktree")
+	}
+
+	if err := m.Unlock("ABC-746"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if entries, _ := m.List(ListOptions{}); entries[0].Locked {
+		t.Errorf("Expected the entry to be unlocked, got %+v", entries[0])
+	}
+	assertCalls(t, g, "lock "+path+" on a USB drive", "lock "+path+" ", "unlock "+path)
+}
+
+// TestLockNotFound tests locking a ticket without a worktree
+func TestLockNotFound(t *testing.T) {
+	m := NewManagerWithGit(newMockGit(), t.TempDir())
+	if err := m.Lock("ABC-746", ""); !errors.Is(err, ErrWorktreeNotFound) {
+		t.Errorf("Expected ErrWorktreeNotFound, got %v", err)
+	}
+	if err := m.Unlock("ABC-746"); !errors.Is(err, ErrWorktreeNotFound) {
+		t.Errorf("Expected ErrWorktreeNotFound, got %v", err)
+	}
+}
+
+// TestDeleteLocked tests that deleting a locked worktree asks for an unlock
+func TestDeleteLocked(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := m.Lock("ABC-746", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err := m.Delete("ABC-746", DeleteOptions{Force: true})
+	if err == nil || !strings.Contains(err.Error(), "unlock it first") {
+		t.Errorf("Expected locked worktree error, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package worktree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// fallbackEditor is used when no editor is configured
+const fallbackEditor = "code"
+
+// Open launches an editor on the worktree for ticket. The editor argument
+// overrides the configured editor and $EDITOR.
+func (m *Manager) Open(ticket, editor string) error {
+	path, err := m.ExistingPath(ticket)
+	if err != nil {
+		return err
+	}
+
+	command, err := resolveEditor(editor, m.config.Editor)
+	if err != nil {
+		return err
+	}
+
+	m.infof("Opening %s with %s...\n", util.Colorize(path, util.ColorBlue), command)
+	args := strings.Fields(command)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor %q: %w", command, err)
+	}
+	return nil
+}
+
+// resolveEditor picks the editor command from the flag, the config file,
+// $EDITOR, and finally `code` on PATH, in that order
+func resolveEditor(flagValue, configured string) (string, error) {
+	for _, candidate := range []string{flagValue, configured, os.Getenv("EDITOR")} {
+		if strings.TrimSpace(candidate) != "" {
+			return candidate, nil
+		}
+	}
+
+	if _, err := exec.LookPath(fallbackEditor); err == nil {
+		return fallbackEditor, nil
+	}
+	return "", errors.New("no editor found: set $EDITOR, the editor config option, or pass --editor")
+}
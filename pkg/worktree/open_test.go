@@ -0,0 +1,40 @@
+package worktree
+
+import (
+	"testing"
+)
+
+// TestResolveEditor tests flag > config > $EDITOR precedence
+func TestResolveEditor(t *testing.T) {
+	t.Setenv("EDITOR", "vim")
+
+	testCases := []struct {
+		flag       string
+		configured string
+		expected   string
+	}{
+		{"nano", "subl -w", "nano"},
+		{"", "subl -w", "subl -w"},
+		{"", "", "vim"},
+	}
+
+	for _, tc := range testCases {
+		got, err := resolveEditor(tc.flag, tc.configured)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != tc.expected {
+			t.Errorf("Expected %q, got %q", tc.expected, got)
+		}
+	}
+}
+
+// TestResolveEditorNone tests the error when no editor is available
+func TestResolveEditorNone(t *testing.T) {
+	t.Setenv("EDITOR", "")
+	t.Setenv("PATH", t.TempDir())
+
+	if _, err := resolveEditor("", ""); err == nil {
+		t.Errorf("Expected error when no editor is available")
+	}
+}
@@ -0,0 +1,45 @@
+package worktree
+
+import (
+	"fmt"
+
+	"github.com/mdelgado509/go-worktree/internal/git"
+)
+
+// githubHosts are the remote hosts that serve github.com repositories
+var githubHosts = map[string]bool{"github.com": true, "www.github.com": true, "ssh.github.com": true}
+
+// CompareURL returns the GitHub page for opening a pull request from the
+// branch checked out in the current directory into base. An empty base
+// uses the configured default base branch, or else the remote's default
+// branch. The repository comes from the URL of the configured remote.
+func (m *Manager) CompareURL(base string) (string, error) {
+	branch, err := m.git.CurrentBranch(m.ctx)
+	if err != nil {
+		return "", err
+	}
+	remote := m.config.RemoteName("")
+	base = m.baseBranch(base, remote)
+	if branch == base {
+		return "", fmt.Errorf("branch %s is the base branch, check out a ticket branch first", branch)
+	}
+
+	remoteURL, err := m.git.RemoteURL(m.ctx, remote)
+	if err != nil {
+		return "", err
+	}
+	return githubCompareURL(remoteURL, base, branch)
+}
+
+// githubCompareURL builds the compare URL for branch against base in the
+// GitHub repository at remoteURL
+func githubCompareURL(remoteURL, base, branch string) (string, error) {
+	if !githubHosts[git.ParseRemoteHost(remoteURL)] {
+		return "", fmt.Errorf("remote %s is not a GitHub repository", remoteURL)
+	}
+	repo, err := git.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://github.com/%s/compare/%s...%s", repo, base, branch), nil
+}
@@ -0,0 +1,65 @@
+package worktree
+
+import (
+	"testing"
+)
+
+// TestGithubCompareURL tests building compare URLs from SSH and HTTPS
+// remotes and rejecting other hosts
+func TestGithubCompareURL(t *testing.T) {
+	testCases := []struct {
+		remoteURL string
+		expected  string
+	}{
+		{"git@github.com:acme/app.git", "https://github.com/acme/app/compare/main...feature/ABC-746"},
+		{"https://github.com/acme/app", "https://github.com/acme/app/compare/main...feature/ABC-746"},
+		{"ssh://git@ssh.github.com:443/acme/app.git", "https://github.com/acme/app/compare/main...feature/ABC-746"},
+	}
+
+	for _, tc := range testCases {
+		result, err := githubCompareURL(tc.remoteURL, "main", "feature/ABC-746")
+		if err != nil {
+			t.Errorf("For %q unexpected error: %v", tc.remoteURL, err)
+			continue
+		}
+		if result != tc.expected {
+			t.Errorf("For %q expected %q, got %q", tc.remoteURL, tc.expected, result)
+		}
+	}
+
+	for _, remoteURL := range []string{"git@gitlab.com:acme/app.git", "/srv/git/acme/app.git"} {
+		if _, err := githubCompareURL(remoteURL, "main", "ABC-746"); err == nil {
+			t.Errorf("Expected error for %q", remoteURL)
+		}
+	}
+}
+
+// TestCompareURL tests comparing the current branch against the base
+func TestCompareURL(t *testing.T) {
+	g := newMockGit()
+	g.remoteURL = "git@github.com:acme/app.git"
+	g.currentBranch = "ABC-746"
+	m := NewManagerWithGit(g, t.TempDir())
+
+	result, err := m.CompareURL("develop")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "https://github.com/acme/app/compare/develop...ABC-746" {
+		t.Errorf("Unexpected URL %q", result)
+	}
+
+	g.defaultBranch = "trunk"
+	result, err = m.CompareURL("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "https://github.com/acme/app/compare/trunk...ABC-746" {
+		t.Errorf("Expected the remote's default branch as base, got %q", result)
+	}
+
+	g.currentBranch = "trunk"
+	if _, err := m.CompareURL(""); err == nil {
+		t.Errorf("Expected error comparing the base branch with itself")
+	}
+}
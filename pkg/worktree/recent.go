@@ -0,0 +1,91 @@
+package worktree
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// RecentEntry is a managed worktree with the time it was last modified
+type RecentEntry struct {
+	Entry
+	Modified time.Time
+}
+
+// Recent returns the managed worktrees sorted newest-first by modification
+// time. A limit above zero keeps only that many entries.
+func (m *Manager) Recent(limit int) ([]RecentEntry, error) {
+	entries, err := m.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	recent := make([]RecentEntry, 0, len(entries))
+	for _, entry := range entries {
+		modified, err := modTime(entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		recent = append(recent, RecentEntry{Entry: entry, Modified: modified})
+	}
+
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].Modified.After(recent[j].Modified)
+	})
+	if limit > 0 && len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return recent, nil
+}
+
+// modTime returns the later of the modification times of a worktree
+// directory and its .git file, which git touches when the worktree is used
+func modTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	modified := info.ModTime()
+
+	if gitInfo, err := os.Stat(filepath.Join(path, ".git")); err == nil && gitInfo.ModTime().After(modified) {
+		modified = gitInfo.ModTime()
+	}
+	return modified, nil
+}
+
+// RenderRecent writes the worktrees for repo with how long ago each was
+// modified
+func RenderRecent(w io.Writer, repo string, entries []RecentEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintf(w, "No worktrees found for repository %s\n", util.Colorize(repo, util.ColorYellow))
+		return
+	}
+
+	fmt.Fprintf(w, "Recent worktrees for repository %s:\n", util.Colorize(repo, util.ColorYellow))
+	for _, entry := range entries {
+		fmt.Fprintf(w, "  %s -> %s (%s) %s\n",
+			util.Colorize(entry.Ticket, util.ColorGreen),
+			entry.Path,
+			util.Colorize(entry.Branch, util.ColorBlue),
+			util.Colorize(formatAge(time.Since(entry.Modified)), util.ColorCyan))
+	}
+}
+
+// formatAge formats a duration as a short relative age such as "3h ago"
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	}
+}
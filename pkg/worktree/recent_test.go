@@ -0,0 +1,87 @@
+package worktree
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// TestRecent tests newest-first ordering and the limit
+func TestRecent(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	now := time.Now()
+	ages := map[string]time.Duration{"ABC-1": 3 * time.Hour, "ABC-2": time.Minute, "ABC-3": 2 * 24 * time.Hour}
+	for ticket, age := range ages {
+		if _, err := m.Create(ticket, CreateOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		path, _ := m.ticketPath(ticket)
+		if err := os.Chtimes(path, now, now.Add(-age)); err != nil {
+			t.Fatalf("Failed to set mtime: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		limit    int
+		expected []string
+	}{
+		{0, []string{"ABC-2", "ABC-1", "ABC-3"}},
+		{2, []string{"ABC-2", "ABC-1"}},
+		{10, []string{"ABC-2", "ABC-1", "ABC-3"}},
+	}
+
+	for _, tc := range testCases {
+		entries, err := m.Recent(tc.limit)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var tickets []string
+		for _, entry := range entries {
+			tickets = append(tickets, entry.Ticket)
+		}
+		if strings.Join(tickets, ",") != strings.Join(tc.expected, ",") {
+			t.Errorf("Limit %d: expected %v, got %v", tc.limit, tc.expected, tickets)
+		}
+	}
+}
+
+// TestRenderRecent tests the recent listing shows each worktree's age
+func TestRenderRecent(t *testing.T) {
+	defer util.SetColorEnabled(util.ColorEnabled())
+	util.SetColorEnabled(false)
+
+	var buf bytes.Buffer
+	RenderRecent(&buf, "repo", []RecentEntry{
+		{Entry: Entry{Ticket: "ABC-746", Path: "/tmp/wt/repo/ABC-746", Branch: "ABC-746"}, Modified: time.Now().Add(-2 * time.Hour)},
+	})
+
+	want := "ABC-746 -> /tmp/wt/repo/ABC-746 (ABC-746) 2h ago"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("Expected output to contain %q, got %q", want, buf.String())
+	}
+}
+
+// TestFormatAge tests relative age formatting
+func TestFormatAge(t *testing.T) {
+	testCases := []struct {
+		age      time.Duration
+		expected string
+	}{
+		{10 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{90 * time.Minute, "1h ago"},
+		{49 * time.Hour, "2d ago"},
+	}
+
+	for _, tc := range testCases {
+		if result := formatAge(tc.age); result != tc.expected {
+			t.Errorf("Expected %q, got %q", tc.expected, result)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package worktree
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// Rename moves the worktree for oldTicket to newTicket and renames its
+// branch to match. The branch is only renamed when it still has the name
+// derived from the old ticket, so custom --branch names are kept. If the
+// branch rename fails the directory move is rolled back.
+func (m *Manager) Rename(oldTicket, newTicket string) error {
+	if err := validateTicket(oldTicket); err != nil {
+		return err
+	}
+	if err := validateTicket(newTicket); err != nil {
+		return err
+	}
+	if err := validateTicketPattern(newTicket, m.config.TicketPattern); err != nil {
+		return err
+	}
+
+	oldTicket, err := m.ResolveTicket(oldTicket)
+	if err != nil {
+		return err
+	}
+
+	oldPath, err := m.ticketPath(oldTicket)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(oldPath); errors.Is(err, fs.ErrNotExist) {
+		return errorf(ErrWorktreeNotFound, "worktree for ticket %s not found", oldTicket)
+	}
+
+	worktreeMap, err := m.registeredWorktrees()
+	if err != nil {
+		return err
+	}
+	oldBranch := m.worktreeBranch(worktreeMap, oldPath, oldTicket)
+	newBranch := m.branchName(newTicket, "")
+	renameBranch := oldBranch == m.branchName(oldTicket, "") && oldBranch != newBranch
+	if renameBranch {
+		m.warnSanitized(newTicket, newBranch)
+	} else {
+		newBranch = oldBranch
+	}
+
+	newPath, err := m.worktreePath(newTicket, newBranch)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(newPath); !errors.Is(err, fs.ErrNotExist) {
+		return errorf(ErrWorktreeExists, "directory already exists: %s", newPath)
+	}
+
+	m.infof("Moving worktree %s to %s...\n",
+		util.Colorize(oldTicket, util.ColorBlue), util.Colorize(newTicket, util.ColorBlue))
+	if err := m.git.MoveWorktree(m.ctx, oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to move worktree: %w", err)
+	}
+
+	if renameBranch {
+		m.infof("Renaming branch %s to %s...\n",
+			util.Colorize(oldBranch, util.ColorBlue), util.Colorize(newBranch, util.ColorBlue))
+		if err := m.git.RenameBranch(m.ctx, oldBranch, newBranch); err != nil {
+			if rollbackErr := m.git.MoveWorktree(m.ctx, newPath, oldPath); rollbackErr != nil {
+				return fmt.Errorf("failed to rename branch: %w (rolling back the move also failed: %v)", err, rollbackErr)
+			}
+			return fmt.Errorf("failed to rename branch, worktree moved back to %s: %w", oldPath, err)
+		}
+	} else {
+		m.infof("Keeping branch %s\n", util.Colorize(oldBranch, util.ColorBlue))
+	}
+
+	m.updateIndex(func(worktrees map[string]IndexEntry) {
+		recorded := worktrees[oldTicket]
+		delete(worktrees, oldTicket)
+		recorded.Branch = newBranch
+		worktrees[newTicket] = recorded
+	})
+	m.successf("Worktree %s renamed to %s at: %s\n", oldTicket, newTicket, newPath)
+	return nil
+}
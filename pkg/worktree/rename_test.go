@@ -0,0 +1,88 @@
+package worktree
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRename tests moving a worktree and renaming its branch along with it,
+// and keeping a custom branch name as it is
+func TestRename(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     CreateOptions
+		expected []string
+	}{
+		{"derived branch", CreateOptions{}, []string{"rename-branch ABC-746 ABC-747"}},
+		{"custom branch", CreateOptions{Branch: "feature/login"}, nil},
+	}
+
+	for _, tc := range testCases {
+		g := newMockGit()
+		m := NewManagerWithGit(g, t.TempDir())
+		if _, err := m.Create("ABC-746", tc.opts); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		oldPath := filepath.Join(m.basePath, "test-repo", "ABC-746")
+		newPath := filepath.Join(m.basePath, "test-repo", "ABC-747")
+
+		g.calls = nil
+		if err := m.Rename("ABC-746", "ABC-747"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		assertCalls(t, g, append([]string{"move " + oldPath + " " + newPath}, tc.expected...)...)
+		if _, err := os.Stat(newPath); err != nil {
+			t.Errorf("%s: expected the worktree at %s: %v", tc.name, newPath, err)
+		}
+	}
+}
+
+// TestRenameRollback tests that the worktree is moved back when renaming
+// its branch fails
+func TestRenameRollback(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	oldPath := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	newPath := filepath.Join(m.basePath, "test-repo", "ABC-747")
+
+	g.calls = nil
+	g.renameErr = errors.New("branch ABC-747 already exists")
+	err := m.Rename("ABC-746", "ABC-747")
+	if err == nil || !strings.Contains(err.Error(), "worktree moved back to "+oldPath) {
+		t.Fatalf("Expected the rename to fail and be rolled back, got %v", err)
+	}
+	assertCalls(t, g,
+		"move "+oldPath+" "+newPath,
+		"rename-branch ABC-746 ABC-747",
+		"move "+newPath+" "+oldPath)
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Errorf("Expected the worktree back at %s: %v", oldPath, err)
+	}
+}
+
+// TestRenameExists tests that a rename onto an existing directory is
+// refused without touching anything
+func TestRenameExists(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	newPath := filepath.Join(m.basePath, "test-repo", "ABC-747")
+	if err := os.MkdirAll(newPath, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	g.calls = nil
+	err := m.Rename("ABC-746", "ABC-747")
+	if !errors.Is(err, ErrWorktreeExists) || !strings.Contains(err.Error(), "directory already exists: "+newPath) {
+		t.Errorf("Expected a directory exists error, got %v", err)
+	}
+	assertCalls(t, g)
+}
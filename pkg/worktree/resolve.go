@@ -0,0 +1,137 @@
+package worktree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CurrentTicketToken refers to the worktree containing the current directory
+const CurrentTicketToken = "@"
+
+// ResolveTicket maps a possibly partial ticket query to the name of an
+// existing worktree directory. An exact match always wins; otherwise a
+// unique case-insensitive substring match is used. Queries that match
+// nothing are returned unchanged so callers can report the missing ticket.
+// The query "@" resolves to the current worktree's ticket.
+func (m *Manager) ResolveTicket(query string) (string, error) {
+	if query == CurrentTicketToken {
+		return m.CurrentTicket()
+	}
+
+	tickets, err := m.Tickets()
+	if err != nil {
+		return "", err
+	}
+	return matchTicket(tickets, query)
+}
+
+// CurrentTicket returns the ticket of the managed worktree containing the
+// current directory
+func (m *Manager) CurrentTicket() (string, error) {
+	repo, err := m.repoName()
+	if err != nil {
+		return "", err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	name, ok := ticketAt(filepath.Join(m.basePath, repo), wd)
+	if !ok {
+		return "", errorf(ErrNotInWorktree, "%s is not inside a worktree managed for %s", wd, repo)
+	}
+	tmpl, err := m.dirTemplate()
+	if err != nil {
+		return "", err
+	}
+	return m.dirTicket(tmpl, name), nil
+}
+
+// TicketForBranch returns the ticket of the managed worktree that has
+// branch checked out, for when the branch is easier to remember than the
+// ticket, e.g. with a branch prefix
+func (m *Manager) TicketForBranch(branch string) (string, error) {
+	dirs, err := m.worktreeDirs()
+	if err != nil {
+		return "", err
+	}
+	worktrees, err := m.git.ListWorktrees(m.ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	worktreeMap := make(map[string]GitWorktree)
+	for _, wt := range worktrees {
+		worktreeMap[wt.Path] = wt
+	}
+
+	var tickets []string
+	for _, dir := range dirs {
+		if wt, ok := lookupPath(worktreeMap, dir.path); ok && !wt.Detached && wt.Branch == branch {
+			tickets = append(tickets, dir.ticket)
+		}
+	}
+
+	switch len(tickets) {
+	case 0:
+		for _, wt := range worktrees {
+			if !wt.Detached && wt.Branch == branch {
+				return "", errorf(ErrWorktreeNotFound, "branch %s is checked out in %s, which is not a managed worktree", branch, wt.Path)
+			}
+		}
+		return "", errorf(ErrWorktreeNotFound, "no worktree has branch %s checked out", branch)
+	case 1:
+		return tickets[0], nil
+	}
+	return "", errorf(ErrAmbiguousTicket, "branch %s is checked out in several worktrees: %s", branch, strings.Join(tickets, ", "))
+}
+
+// ticketAt returns the ticket directory under repoPath that contains dir,
+// comparing resolved paths so symlinked base paths still match
+func ticketAt(repoPath, dir string) (string, bool) {
+	if resolved, err := filepath.EvalSymlinks(repoPath); err == nil {
+		repoPath = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	rel, err := filepath.Rel(repoPath, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	ticket, _, _ := strings.Cut(rel, string(filepath.Separator))
+	return ticket, true
+}
+
+// matchTicket picks the ticket matching query from tickets
+func matchTicket(tickets []string, query string) (string, error) {
+	lowerQuery := strings.ToLower(query)
+
+	var matches []string
+	for _, ticket := range tickets {
+		if ticket == query {
+			return ticket, nil
+		}
+		if strings.Contains(strings.ToLower(ticket), lowerQuery) {
+			matches = append(matches, ticket)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return query, nil
+	case 1:
+		return matches[0], nil
+	}
+
+	// Prefer a case-insensitive exact match over substring matches
+	for _, ticket := range matches {
+		if strings.EqualFold(ticket, query) {
+			return ticket, nil
+		}
+	}
+	return "", errorf(ErrAmbiguousTicket, "ticket %q is ambiguous, matches: %s", query, strings.Join(matches, ", "))
+}
@@ -0,0 +1,136 @@
+package worktree
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMatchTicket tests exact, fuzzy, and missing ticket resolution
+func TestMatchTicket(t *testing.T) {
+	tickets := []string{"ABC-746", "ABC-7460", "XYZ-12", "abc-746"}
+
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{"ABC-746", "ABC-746"},
+		{"abc-746", "abc-746"},
+		{"xyz", "XYZ-12"},
+		{"12", "XYZ-12"},
+		{"7460", "ABC-7460"},
+		{"NOPE-1", "NOPE-1"},
+	}
+
+	for _, tc := range testCases {
+		got, err := matchTicket(tickets, tc.query)
+		if err != nil {
+			t.Errorf("For %q unexpected error: %v", tc.query, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("For %q expected %q, got %q", tc.query, tc.expected, got)
+		}
+	}
+}
+
+// TestMatchTicketAmbiguous tests that ambiguous queries list the candidates
+func TestMatchTicketAmbiguous(t *testing.T) {
+	_, err := matchTicket([]string{"ABC-746", "ABC-800", "XYZ-12"}, "abc")
+	if err == nil {
+		t.Fatalf("Expected ambiguity error")
+	}
+	for _, candidate := range []string{"ABC-746", "ABC-800"} {
+		if !strings.Contains(err.Error(), candidate) {
+			t.Errorf("Expected error to list %s, got %v", candidate, err)
+		}
+	}
+	if strings.Contains(err.Error(), "XYZ-12") {
+		t.Errorf("Expected error not to list XYZ-12, got %v", err)
+	}
+}
+
+// TestMatchTicketCaseInsensitiveExact tests that a case-insensitive exact
+// match beats longer substring matches
+func TestMatchTicketCaseInsensitiveExact(t *testing.T) {
+	got, err := matchTicket([]string{"ABC-746", "ABC-7460"}, "abc-746")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != "ABC-746" {
+		t.Errorf("Expected ABC-746, got %s", got)
+	}
+}
+
+// TestTicketForBranch tests finding a managed worktree by its branch, and
+// the errors for unknown, unmanaged, and ambiguous branches
+func TestTicketForBranch(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	m.config.BranchPrefix = "feature/"
+	for _, ticket := range []string{"ABC-746", "ABC-747"} {
+		if _, err := m.Create(ticket, CreateOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	g.worktrees = append(g.worktrees, GitWorktree{Path: "/tmp/scratch", Branch: "spike"})
+
+	ticket, err := m.TicketForBranch("feature/ABC-747")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ticket != "ABC-747" {
+		t.Errorf("Expected ABC-747, got %s", ticket)
+	}
+
+	testCases := []struct {
+		branch   string
+		kind     error
+		expected string
+	}{
+		{"ABC-746", ErrWorktreeNotFound, "no worktree has branch ABC-746 checked out"},
+		{"spike", ErrWorktreeNotFound, "branch spike is checked out in /tmp/scratch, which is not a managed worktree"},
+	}
+	for _, tc := range testCases {
+		_, err := m.TicketForBranch(tc.branch)
+		if !errors.Is(err, tc.kind) || err.Error() != tc.expected {
+			t.Errorf("For %s expected %q, got %v", tc.branch, tc.expected, err)
+		}
+	}
+
+	// git only allows this for branches checked out with --force
+	for i := range g.worktrees {
+		if g.worktrees[i].Branch == "feature/ABC-747" {
+			g.worktrees[i].Branch = "feature/ABC-746"
+		}
+	}
+	if _, err := m.TicketForBranch("feature/ABC-746"); !errors.Is(err, ErrAmbiguousTicket) ||
+		!strings.Contains(err.Error(), "ABC-746, ABC-747") {
+		t.Errorf("Expected ErrAmbiguousTicket listing both tickets, got %v", err)
+	}
+}
+
+// TestTicketAt tests finding the ticket directory containing a path
+func TestTicketAt(t *testing.T) {
+	repoPath := filepath.Join("/wt", "repo")
+	testCases := []struct {
+		dir      string
+		expected string
+		ok       bool
+	}{
+		{filepath.Join(repoPath, "ABC-746"), "ABC-746", true},
+		{filepath.Join(repoPath, "ABC-746", "src", "pkg"), "ABC-746", true},
+		{repoPath, "", false},
+		{filepath.Join("/wt", "other", "ABC-746"), "", false},
+		{filepath.Join("/wt", "repo-2", "ABC-746"), "", false},
+		{"/home/user", "", false},
+	}
+
+	for _, tc := range testCases {
+		ticket, ok := ticketAt(repoPath, tc.dir)
+		if ticket != tc.expected || ok != tc.ok {
+			t.Errorf("ticketAt(%q) expected (%q, %v), got (%q, %v)", tc.dir, tc.expected, tc.ok, ticket, ok)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDirSize tests summing file sizes across nested directories
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "nested"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	files := map[string]int{"a.txt": 100, "nested/b.txt": 2048}
+	for name, size := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	size, err := dirSize(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if size != 2148 {
+		t.Errorf("Expected 2148 bytes, got %d", size)
+	}
+}
+
+// TestFormatBytes tests human-readable byte formatting
+func TestFormatBytes(t *testing.T) {
+	testCases := []struct {
+		size     int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GB"},
+	}
+
+	for _, tc := range testCases {
+		if got := formatBytes(tc.size); got != tc.expected {
+			t.Errorf("formatBytes(%d) expected %q, got %q", tc.size, tc.expected, got)
+		}
+	}
+}
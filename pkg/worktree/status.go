@@ -0,0 +1,91 @@
+package worktree
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mdelgado509/go-worktree/internal/git"
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// Status describes the branch and uncommitted changes of a worktree
+type Status = git.Status
+
+// StatusEntry describes the working tree state of a managed worktree
+type StatusEntry struct {
+	Entry
+	Status Status
+	// StatusErr is why the status couldn't be read; a failure to read the
+	// entry itself stays in Entry.Err
+	StatusErr error
+}
+
+// err returns every failure recorded for the worktree, or nil
+func (s StatusEntry) err() error {
+	return errors.Join(s.Entry.Err, s.StatusErr)
+}
+
+// MarshalJSON encodes the status as the entry's fields with the number of
+// modified files and whether the worktree is clean. Both are left out when
+// the status couldn't be read, and the entry's and status's errors are
+// encoded as "error".
+func (s StatusEntry) MarshalJSON() ([]byte, error) {
+	type entry Entry
+	encoded := struct {
+		entry
+		Modified *int   `json:"modified,omitempty"`
+		Clean    *bool  `json:"clean,omitempty"`
+		Error    string `json:"error,omitempty"`
+	}{entry: entry(s.Entry)}
+	if s.Status.Branch != "" {
+		encoded.Branch = s.Status.Branch
+	}
+	if err := s.err(); err != nil {
+		encoded.Error = err.Error()
+	} else {
+		clean := s.Status.Clean()
+		encoded.Modified, encoded.Clean = &s.Status.Modified, &clean
+	}
+	return json.Marshal(encoded)
+}
+
+// Statuses gathers the working tree state of every managed worktree.
+// Failures for individual worktrees are recorded on the entry.
+func (m *Manager) Statuses() ([]StatusEntry, error) {
+	entries, err := m.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]StatusEntry, 0, len(entries))
+	for _, entry := range entries {
+		status, err := m.git.WorktreeStatus(m.ctx, entry.Path)
+		statuses = append(statuses, StatusEntry{Entry: entry, Status: status, StatusErr: err})
+	}
+	return statuses, nil
+}
+
+// RenderStatus writes the human-readable status listing for repo
+func RenderStatus(w io.Writer, repo string, statuses []StatusEntry) {
+	if len(statuses) == 0 {
+		fmt.Fprintf(w, "No worktrees found for repository %s\n", util.Colorize(repo, util.ColorYellow))
+		return
+	}
+
+	fmt.Fprintf(w, "Status for repository %s:\n", util.Colorize(repo, util.ColorYellow))
+	for _, s := range statuses {
+		switch {
+		case s.err() != nil:
+			fmt.Fprintf(w, "  %s (%s)\n", util.Colorize(s.Ticket, util.ColorRed), s.err())
+		case s.Status.Clean():
+			fmt.Fprintf(w, "  %s [%s] clean\n",
+				util.Colorize(s.Ticket, util.ColorGreen), s.Status.Branch)
+		default:
+			fmt.Fprintf(w, "  %s [%s] %d modified file%s\n",
+				util.Colorize(s.Ticket, util.ColorYellow), s.Status.Branch,
+				s.Status.Modified, plural(s.Status.Modified, "", "s"))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package worktree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mdelgado509/go-worktree/internal/git"
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// TestRenderStatus tests the clean, dirty, and error status lines, for
+// errors reading the status or the entry
+func TestRenderStatus(t *testing.T) {
+	defer util.SetColorEnabled(util.ColorEnabled())
+	util.SetColorEnabled(false)
+
+	var buf bytes.Buffer
+	RenderStatus(&buf, "repo", []StatusEntry{
+		{Entry: Entry{Ticket: "ABC-1"}, Status: git.Status{Branch: "ABC-1"}},
+		{Entry: Entry{Ticket: "ABC-2"}, Status: git.Status{Branch: "ABC-2", Modified: 3}},
+		{Entry: Entry{Ticket: "ABC-3"}, StatusErr: errors.New("boom")},
+		{Entry: Entry{Ticket: "ABC-4", Err: errors.New("unreadable")}},
+	})
+
+	output := buf.String()
+	for _, want := range []string{"ABC-1 [ABC-1] clean", "ABC-2 [ABC-2] 3 modified files", "ABC-3 (boom)", "ABC-4 (unreadable)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, output)
+		}
+	}
+}
+
+// TestStatusEntryJSON tests encoding clean, dirty, and unreadable statuses
+func TestStatusEntryJSON(t *testing.T) {
+	testCases := []struct {
+		status   StatusEntry
+		expected string
+	}{
+		{
+			StatusEntry{Entry: Entry{Ticket: "ABC-1", Path: "/wt/ABC-1", Branch: "ABC-1"}, Status: git.Status{Branch: "ABC-1"}},
+			`{"ticket":"ABC-1","path":"/wt/ABC-1","branch":"ABC-1","modified":0,"clean":true}`,
+		},
+		{
+			StatusEntry{Entry: Entry{Ticket: "ABC-2", Path: "/wt/ABC-2", Branch: "detached"}, Status: git.Status{Branch: "ABC-2", Modified: 3}},
+			`{"ticket":"ABC-2","path":"/wt/ABC-2","branch":"ABC-2","modified":3,"clean":false}`,
+		},
+		{
+			StatusEntry{Entry: Entry{Ticket: "ABC-3", Path: "/wt/ABC-3", Branch: "ABC-3"}, StatusErr: errors.New("boom")},
+			`{"ticket":"ABC-3","path":"/wt/ABC-3","branch":"ABC-3","error":"boom"}`,
+		},
+		{
+			StatusEntry{Entry: Entry{Ticket: "ABC-4", Path: "/wt/ABC-4", Err: errors.New("unreadable")}},
+			`{"ticket":"ABC-4","path":"/wt/ABC-4","branch":"","error":"unreadable"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		data, err := json.Marshal(tc.status)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(data) != tc.expected {
+			t.Errorf("Expected %s, got %s", tc.expected, data)
+		}
+	}
+}
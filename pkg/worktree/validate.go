@@ -0,0 +1,115 @@
+package worktree
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// validateTicket rejects ticket IDs that would escape or break the
+// worktree directory layout
+func validateTicket(ticket string) error {
+	if strings.TrimSpace(ticket) == "" {
+		return errorf(ErrInvalidTicket, "ticket ID must not be empty")
+	}
+	if ticket == "." || ticket == ".." {
+		return errorf(ErrInvalidTicket, "invalid ticket ID %q: must not be a relative path", ticket)
+	}
+	if strings.ContainsAny(ticket, `/\`) {
+		return errorf(ErrInvalidTicket, "invalid ticket ID %q: must not contain path separators", ticket)
+	}
+	for _, r := range ticket {
+		if unicode.IsControl(r) {
+			return errorf(ErrInvalidTicket, "invalid ticket ID %q: must not contain control characters", ticket)
+		}
+	}
+	return nil
+}
+
+// validateTicketPattern checks ticket against the configured pattern, if any
+func validateTicketPattern(ticket, pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid ticket_pattern %q: %w", pattern, err)
+	}
+	if !re.MatchString(ticket) {
+		return errorf(ErrInvalidTicket, "ticket ID %q does not match the required pattern %s", ticket, pattern)
+	}
+	return nil
+}
+
+// sanitizeBranchName turns name into a valid git branch name by the rules
+// of git check-ref-format. Characters git doesn't allow, and the sequences
+// ".." and "@{", are replaced by a dash, a run of them by a single one. In
+// each slash-separated component leading dots are dropped and a ".lock"
+// ending becomes "-lock", after dropping trailing dots from the name.
+// Valid names are returned unchanged.
+func sanitizeBranchName(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	dashed := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		illegal := unicode.IsControl(r) || strings.ContainsRune(" ~^:?*[\\", r)
+		switch {
+		case r == '.' && i+1 < len(runes) && runes[i+1] == '.':
+			for i+1 < len(runes) && runes[i+1] == '.' {
+				i++
+			}
+			illegal = true
+		case r == '@' && i+1 < len(runes) && runes[i+1] == '{':
+			i++
+			illegal = true
+		}
+		if illegal {
+			if !dashed {
+				b.WriteByte('-')
+			}
+			dashed = true
+			continue
+		}
+		b.WriteRune(r)
+		dashed = false
+	}
+
+	components := strings.Split(strings.TrimRight(b.String(), "."), "/")
+	for i, component := range components {
+		component = strings.TrimLeft(component, ".")
+		if base, ok := strings.CutSuffix(component, ".lock"); ok {
+			component = base + "-lock"
+		}
+		if component == "" {
+			component = "-"
+		}
+		components[i] = component
+	}
+	sanitized := strings.Join(components, "/")
+	if sanitized == "@" {
+		return "-"
+	}
+	return sanitized
+}
+
+// normalizeRepoName makes a repository name safe and predictable as a
+// directory under the base path: a trailing ".git" is dropped, each run of
+// whitespace becomes a dash, and with lowercase the name is lowercased.
+// Each slash-separated component of an org/repo name is normalized on its
+// own. A component that would end up empty is kept as it was.
+func normalizeRepoName(name string, lowercase bool) string {
+	components := strings.Split(name, "/")
+	for i, component := range components {
+		normalized := strings.Join(strings.Fields(strings.TrimSuffix(component, ".git")), "-")
+		if lowercase {
+			normalized = strings.ToLower(normalized)
+		}
+		if normalized != "" {
+			components[i] = normalized
+		}
+	}
+	return strings.Join(components, "/")
+}
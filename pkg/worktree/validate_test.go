@@ -0,0 +1,100 @@
+package worktree
+
+import (
+	"testing"
+)
+
+// TestValidateTicket tests accepted and rejected ticket IDs
+func TestValidateTicket(t *testing.T) {
+	valid := []string{"ABC-746", "feature_x", "v1.2", "a..b"}
+	for _, ticket := range valid {
+		if err := validateTicket(ticket); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", ticket, err)
+		}
+	}
+
+	invalid := []string{"", "  ", ".", "..", "../etc", "a/b", `a\b`, "abc\n", "tab\there"}
+	for _, ticket := range invalid {
+		if err := validateTicket(ticket); err == nil {
+			t.Errorf("Expected %q to be rejected", ticket)
+		}
+	}
+}
+
+// TestValidateTicketPattern tests the optional team convention regex
+func TestValidateTicketPattern(t *testing.T) {
+	pattern := `^[A-Z]+-\d+$`
+
+	if err := validateTicketPattern("ABC-746", pattern); err != nil {
+		t.Errorf("Expected ABC-746 to match, got %v", err)
+	}
+	if err := validateTicketPattern("abc-746", pattern); err == nil {
+		t.Errorf("Expected abc-746 to be rejected")
+	}
+	if err := validateTicketPattern("anything", ""); err != nil {
+		t.Errorf("Expected empty pattern to allow anything, got %v", err)
+	}
+	if err := validateTicketPattern("ABC-746", "[unclosed"); err == nil {
+		t.Errorf("Expected invalid pattern to be reported")
+	}
+}
+
+// TestSanitizeBranchName tests replacing what git disallows in branch names
+func TestSanitizeBranchName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"ABC-746", "ABC-746"},
+		{"feature/ABC-746", "feature/ABC-746"},
+		{"v1.2", "v1.2"},
+		{"ABC 746", "ABC-746"},
+		{"PROJ~1^2", "PROJ-1-2"},
+		{"team: fix", "team-fix"},
+		{"what?*[x]", "what-x]"},
+		{`back\slash`, "back-slash"},
+		{"tab\there", "tab-here"},
+		{"a..b", "a-b"},
+		{"a....b", "a-b"},
+		{"x@{1}", "x-1}"},
+		{"user@example", "user@example"},
+		{"@", "-"},
+		{".hidden", "hidden"},
+		{"feature/.hidden", "feature/hidden"},
+		{"release.lock", "release-lock"},
+		{"feature/a.lock", "feature/a-lock"},
+		{"ends.", "ends"},
+		{"ends.lock.", "ends-lock"},
+	}
+
+	for _, tc := range testCases {
+		if got := sanitizeBranchName(tc.name); got != tc.expected {
+			t.Errorf("sanitizeBranchName(%q): expected %q, got %q", tc.name, tc.expected, got)
+		}
+	}
+}
+
+// TestNormalizeRepoName tests stripping ".git", replacing spaces, and
+// optional lowercasing of repository names
+func TestNormalizeRepoName(t *testing.T) {
+	testCases := []struct {
+		name      string
+		lowercase bool
+		expected  string
+	}{
+		{"go-worktree", false, "go-worktree"},
+		{"go-worktree.git", false, "go-worktree"},
+		{"My Repo", false, "My-Repo"},
+		{" My  Repo\t.git", false, "My-Repo"},
+		{"My Repo.git", true, "my-repo"},
+		{"Acme/Web App", true, "acme/web-app"},
+		{"acme/app.git", false, "acme/app"},
+		{".git", false, ".git"},
+	}
+
+	for _, tc := range testCases {
+		if got := normalizeRepoName(tc.name, tc.lowercase); got != tc.expected {
+			t.Errorf("normalizeRepoName(%q, %t): expected %q, got %q", tc.name, tc.lowercase, tc.expected, got)
+		}
+	}
+}
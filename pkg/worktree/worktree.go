@@ -0,0 +1,1591 @@
+// Package worktree manages git worktrees organized by repository and
+// ticket ID under a common base path.
+//
+// A Manager operates on the repository containing the current directory:
+//
+//	m := worktree.NewManager()
+//	created, err := m.Create("ABC-746", worktree.CreateOptions{})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println("created", created.Path)
+//	entries, err := m.List(worktree.ListOptions{})
+//
+// Methods return data and errors rather than printing. Progress messages
+// are discarded unless an output writer is set with SetOutput.
+package worktree
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mdelgado509/go-worktree/internal/config"
+	"github.com/mdelgado509/go-worktree/internal/git"
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// GitWorktree is a worktree as reported by GitClient.ListWorktrees
+type GitWorktree = git.Worktree
+
+// FetchOptions controls how GitClient.FetchBranch fetches
+type FetchOptions = git.FetchOptions
+
+// DefaultFetchRetries is the number of fetch retries the CLI uses
+const DefaultFetchRetries = git.DefaultFetchRetries
+
+// GitClient is the set of git operations Manager relies on. *git.Client
+// implements it; tests substitute a mock.
+type GitClient interface {
+	IsInsideRepo(ctx context.Context) (bool, error)
+	IsBareRepo(ctx context.Context) (bool, error)
+	GetRepoName(ctx context.Context) (string, error)
+	GetRepoIdentifier(ctx context.Context, remote string) (string, error)
+	RemoteURL(ctx context.Context, remote string) (string, error)
+	Toplevel(ctx context.Context) (string, error)
+	CurrentBranch(ctx context.Context) (string, error)
+	UpstreamOf(ctx context.Context, branch string) (string, error)
+	DefaultBranch(ctx context.Context, remote string) (string, error)
+	FetchBranch(ctx context.Context, remote, branch string, opts FetchOptions) error
+	CreateWorktree(ctx context.Context, path, branchName, startPoint string) error
+	CreateTrackingWorktree(ctx context.Context, path, branchName, remote string) error
+	AddWorktree(ctx context.Context, path, branchName string) error
+	CreateBranch(ctx context.Context, branchName, startPoint string) error
+	SetUpstream(ctx context.Context, path, remote, branch string) error
+	LocalBranchExists(ctx context.Context, branchName string) (bool, error)
+	RemoteBranchExists(ctx context.Context, remote, branch string) (bool, error)
+	IgnoredPaths(ctx context.Context, paths []string) (map[string]bool, error)
+	BranchExists(ctx context.Context, ref string) (bool, error)
+	RemoveWorktree(ctx context.Context, path string, force bool) error
+	MoveWorktree(ctx context.Context, oldPath, newPath string) error
+	LockWorktree(ctx context.Context, path, reason string) error
+	UnlockWorktree(ctx context.Context, path string) error
+	RenameBranch(ctx context.Context, oldName, newName string) error
+	DeleteBranch(ctx context.Context, branchName string) error
+	ListLocalBranches(ctx context.Context) ([]string, error)
+	MergedBranches(ctx context.Context, base string) ([]string, error)
+	BranchCheckedOutAt(ctx context.Context, branch string) (string, error)
+	ListWorktrees(ctx context.Context) ([]GitWorktree, error)
+	WorktreeStatus(ctx context.Context, path string) (Status, error)
+}
+
+// Manager handles worktree operations
+type Manager struct {
+	git      GitClient
+	basePath string
+	config   *config.Config
+	// out receives progress messages; io.Discard unless set with SetOutput
+	out io.Writer
+	// errOut receives the error output of hooks; os.Stderr
+	errOut io.Writer
+	// animate shows a spinner on out during slow steps such as fetching;
+	// only set it when out is a terminal
+	animate bool
+	// ctx bounds every git command; context.Background unless set with
+	// SetContext
+	ctx context.Context
+	// dryRun receives the commands Create and Delete would run instead of
+	// running them; nil runs them
+	dryRun io.Writer
+	// repoNames caches repoName by working directory, since library users
+	// may change directory between calls
+	repoNames map[string]string
+}
+
+// BasePathEnv is the environment variable that overrides the worktree base path
+const BasePathEnv = "GO_WORKTREE_HOME"
+
+// NewManager creates a new worktree manager using the user's config file
+// and the current repository's .go-worktree file, if any
+func NewManager() *Manager {
+	cfg, err := config.Load()
+	if err != nil {
+		util.Warn("%v\n", err)
+	}
+	applyRepoFile(cfg)
+	return newManager(cfg)
+}
+
+// applyRepoFile lets a .go-worktree file at the root of the current
+// repository override the configured base path
+func applyRepoFile(cfg *config.Config) {
+	worktrees, err := newGitClient(cfg).ListWorktrees(context.Background())
+	if err != nil || len(worktrees) == 0 {
+		// Outside a repository there is nothing to override
+		return
+	}
+
+	// git lists the main worktree first, so every worktree of the
+	// repository reads the same file
+	repoCfg, err := config.LoadRepoFile(worktrees[0].Path)
+	if err != nil {
+		util.Warn("%v\n", err)
+		return
+	}
+	if repoCfg.BasePath != "" {
+		cfg.BasePath = repoCfg.BasePath
+	}
+}
+
+// IsInsideRepo reports whether the current directory is inside a git repository
+func (m *Manager) IsInsideRepo() (bool, error) {
+	return m.git.IsInsideRepo(m.ctx)
+}
+
+// newManager creates a worktree manager from an already loaded config
+func newManager(cfg *config.Config) *Manager {
+	basePath, err := getWorktreeBasePath(cfg.BasePath)
+	if err != nil {
+		// Fall back to an absolute path so Create never sees a literal "~"
+		basePath = filepath.Join(os.TempDir(), "worktrees")
+	}
+
+	client := newGitClient(cfg)
+	if util.Verbose() {
+		client.SetLogger(os.Stderr)
+	}
+	if cfg.FetchTimeout > 0 {
+		client.SetFetchTimeout(cfg.FetchTimeout)
+	}
+
+	return &Manager{
+		git:      client,
+		basePath: basePath,
+		config:   cfg,
+		out:      io.Discard,
+		errOut:   os.Stderr,
+		ctx:      context.Background(),
+	}
+}
+
+// newGitClient creates a git client running the configured git executable
+func newGitClient(cfg *config.Config) *git.Client {
+	client := git.NewClient()
+	client.SetBinary(git.ResolveBinary(cfg.GitPath))
+	return client
+}
+
+// BasePath returns the absolute directory worktrees are kept under
+func (m *Manager) BasePath() string {
+	return m.basePath
+}
+
+// SetOutput makes the manager write progress messages, such as the steps
+// of Create, to w. Hook output is written there as well.
+func (m *Manager) SetOutput(w io.Writer) {
+	m.out = w
+}
+
+// SetAnimate turns on a spinner during slow steps such as fetching. Only
+// enable it when the output set with SetOutput is a terminal.
+func (m *Manager) SetAnimate(animate bool) {
+	m.animate = animate
+}
+
+// SetContext makes the manager run git commands under ctx, so cancelling
+// it aborts the operation in progress
+func (m *Manager) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetDryRun makes Create and Delete write the commands they would run,
+// including directory creation and hooks, to w without changing anything.
+// A nil w turns dry-run off.
+func (m *Manager) SetDryRun(w io.Writer) {
+	m.dryRun = w
+	if client, ok := m.git.(interface{ SetDryRun(io.Writer) }); ok {
+		client.SetDryRun(w)
+	}
+}
+
+// dryRunf writes a command that dry-run mode skipped
+func (m *Manager) dryRunf(args ...string) {
+	fmt.Fprintf(m.dryRun, "[dry-run] %s\n", util.ShellJoin(args...))
+}
+
+// ensureBasePath creates the base path on first use, reporting where
+// worktrees will be kept
+func (m *Manager) ensureBasePath() error {
+	if _, err := os.Stat(m.basePath); !errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if m.dryRun != nil {
+		m.dryRunf("mkdir", "-p", m.basePath)
+		return nil
+	}
+	if err := os.MkdirAll(m.basePath, 0755); err != nil {
+		return fmt.Errorf("failed to create base path %s: %w", m.basePath, err)
+	}
+	m.infof("Created worktree base path %s\n", util.Colorize(m.basePath, util.ColorBlue))
+	return nil
+}
+
+// infof writes a progress message to the manager's output
+func (m *Manager) infof(format string, args ...any) {
+	fmt.Fprintf(m.out, format, args...)
+}
+
+// successf writes a message after "Success!" to the progress output
+func (m *Manager) successf(format string, args ...any) {
+	util.SuccessTo(m.out, format, args...)
+}
+
+// warnf writes a message after "Warning:" to the progress output
+func (m *Manager) warnf(format string, args ...any) {
+	util.WarnTo(m.out, format, args...)
+}
+
+// NewManagerWithGit creates a worktree manager that runs git operations
+// through client and keeps worktrees under basePath. No config file is
+// read, so built-in defaults apply.
+func NewManagerWithGit(client GitClient, basePath string) *Manager {
+	if resolved, err := absPath(basePath); err == nil {
+		basePath = resolved
+	}
+	return &Manager{
+		git:      client,
+		basePath: basePath,
+		config:   &config.Config{},
+		out:      io.Discard,
+		errOut:   os.Stderr,
+		ctx:      context.Background(),
+	}
+}
+
+// getWorktreeBasePath returns the base path for worktrees. GO_WORKTREE_HOME
+// takes precedence over the configured path, which takes precedence over
+// the default of ~/worktrees.
+func getWorktreeBasePath(configured string) (string, error) {
+	if env := os.Getenv(BasePathEnv); env != "" {
+		return absPath(env)
+	}
+	if configured != "" {
+		return absPath(configured)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find home directory: %w", err)
+	}
+	return filepath.Join(home, "worktrees"), nil
+}
+
+// expandHome expands a leading ~ in path to the user's home directory
+func expandHome(path string) (string, error) {
+	if !util.HasHomePrefix(path) {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find home directory: %w", err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// absPath expands a leading ~ and returns the cleaned absolute form of
+// path, so printed paths work in scripts and from any directory
+func absPath(path string) (string, error) {
+	expanded, err := expandHome(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(expanded)
+}
+
+// RepoName returns the name of the current repository's directory under
+// the base path
+func (m *Manager) RepoName() (string, error) {
+	return m.repoName()
+}
+
+// repoName returns the namespace directory for the current repository
+// under the base path, following the configured repo_key strategy. Every
+// path computation goes through here so worktrees are never orphaned. The
+// result is cached per working directory, so git is asked only once.
+func (m *Manager) repoName() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return m.lookupRepoName()
+	}
+	if repo, ok := m.repoNames[wd]; ok {
+		return repo, nil
+	}
+
+	repo, err := m.lookupRepoName()
+	if err != nil {
+		return "", err
+	}
+	if err := m.checkRepoDir(repo); err != nil {
+		return "", err
+	}
+	if m.repoNames == nil {
+		m.repoNames = make(map[string]string)
+	}
+	m.repoNames[wd] = repo
+	return repo, nil
+}
+
+// checkRepoDir refuses a base path that would put the repository's
+// worktree directory at its main checkout or above it, such as a
+// .go-worktree base_path of "..". Worktrees would then be created inside
+// the checkout, and prune could remove parts of it.
+func (m *Manager) checkRepoDir(repo string) error {
+	worktrees, err := m.git.ListWorktrees(m.ctx)
+	if err != nil || len(worktrees) == 0 {
+		return nil
+	}
+	// git lists the main worktree first; a bare repository has none
+	mainPath := worktrees[0].Path
+	if worktrees[0].Bare || !isDir(filepath.Join(mainPath, ".git")) {
+		return nil
+	}
+
+	basePath := m.basePath
+	if resolved, err := filepath.EvalSymlinks(basePath); err == nil {
+		basePath = resolved
+	}
+	repoDir := filepath.Join(basePath, repo)
+	if pathContains(repoDir, mainPath) {
+		return fmt.Errorf("base path %s puts the worktrees of %s at %s, which holds the repository itself; "+
+			"choose a base path outside %s", m.basePath, repo, repoDir, mainPath)
+	}
+	return nil
+}
+
+// pathContains reports whether path is dir or lies under it
+func pathContains(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// ResetCache forgets cached repository details, for library users whose
+// repository changes without a change of directory, such as when its
+// origin remote is edited
+func (m *Manager) ResetCache() {
+	m.repoNames = nil
+}
+
+// lookupRepoName asks git for the repository's namespace directory and
+// normalizes it. A directory already made under the name as git reports
+// it is kept, so worktrees created before normalization aren't orphaned.
+func (m *Manager) lookupRepoName() (string, error) {
+	name, err := m.gitRepoName()
+	if err != nil {
+		return "", err
+	}
+	normalized := normalizeRepoName(name, m.config.LowercaseRepoName)
+	if normalized != name && isDir(filepath.Join(m.basePath, name)) && !isDir(filepath.Join(m.basePath, normalized)) {
+		return name, nil
+	}
+	return normalized, nil
+}
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// gitRepoName returns the repository's name as git reports it, following
+// the configured repo_key strategy and, for the remote strategy,
+// namespace_from
+func (m *Manager) gitRepoName() (string, error) {
+	repoKey, remotes := m.config.RepoKey, []string{config.NamespaceFromOrigin}
+	switch m.config.NamespaceFrom {
+	case "", config.NamespaceFromOrigin:
+	case config.NamespaceFromUpstream:
+		if repoKey == config.RepoKeyBasename {
+			return "", fmt.Errorf("namespace_from %q needs repo_key %q", m.config.NamespaceFrom, config.RepoKeyRemote)
+		}
+		repoKey, remotes = config.RepoKeyRemote, []string{config.NamespaceFromUpstream, config.NamespaceFromOrigin}
+	default:
+		return "", fmt.Errorf("invalid namespace_from %q: expected %q or %q",
+			m.config.NamespaceFrom, config.NamespaceFromOrigin, config.NamespaceFromUpstream)
+	}
+
+	switch repoKey {
+	case "", config.RepoKeyBasename:
+		return m.git.GetRepoName(m.ctx)
+	case config.RepoKeyRemote:
+		for _, remote := range remotes {
+			if identifier, err := m.git.GetRepoIdentifier(m.ctx, remote); err == nil {
+				return filepath.FromSlash(identifier), nil
+			}
+		}
+		// Local-only repos have no remote to key on
+		return m.git.GetRepoName(m.ctx)
+	default:
+		return "", fmt.Errorf("invalid repo_key %q: expected %q or %q",
+			m.config.RepoKey, config.RepoKeyBasename, config.RepoKeyRemote)
+	}
+}
+
+// branchName returns the branch name used for a ticket. An explicit
+// override wins over the configured prefix. A derived name is sanitized,
+// since ticket IDs may contain characters git doesn't allow in branch
+// names; an override is left for git to check.
+func (m *Manager) branchName(ticket, override string) string {
+	if override != "" {
+		return override
+	}
+	return sanitizeBranchName(m.config.BranchPrefix + ticket)
+}
+
+// warnSanitized warns when the branch derived from ticket had to be changed
+// to be a valid git branch name
+func (m *Manager) warnSanitized(ticket, branch string) {
+	if branch != m.config.BranchPrefix+ticket {
+		m.warnf("ticket %s has characters git doesn't allow in branch names, using branch %s\n",
+			ticket, util.Colorize(branch, util.ColorBlue))
+	}
+}
+
+// worktreeBranch returns the branch checked out in the worktree at path,
+// falling back to the derived branch name when git doesn't report one
+func (m *Manager) worktreeBranch(worktreeMap map[string]string, path, ticket string) string {
+	if branch, ok := lookupPath(worktreeMap, path); ok && branch != "detached" && branch != "" {
+		return branch
+	}
+	return m.branchName(ticket, "")
+}
+
+// GetPath returns the path for a specific worktree. The ticket may be a
+// partial match as accepted by ResolveTicket.
+func (m *Manager) GetPath(ticket string) (string, error) {
+	if err := validateTicket(ticket); err != nil {
+		return "", err
+	}
+
+	ticket, err := m.ResolveTicket(ticket)
+	if err != nil {
+		return "", err
+	}
+
+	return m.ticketPath(ticket)
+}
+
+// ExistingPath is like GetPath but fails with ErrWorktreeNotFound when no
+// worktree directory exists on disk for the ticket
+func (m *Manager) ExistingPath(ticket string) (string, error) {
+	path, err := m.GetPath(ticket)
+	if err != nil {
+		return "", err
+	}
+	if !isDir(path) {
+		return "", errorf(ErrWorktreeNotFound, "worktree for ticket %s not found", ticket)
+	}
+	return path, nil
+}
+
+// ticketPath returns the worktree path for an exact ticket name
+func (m *Manager) ticketPath(ticket string) (string, error) {
+	return m.worktreePath(ticket, m.branchName(ticket, ""))
+}
+
+// worktreePath returns the existing worktree directory for ticket, or else
+// the one the dir_template gives a new worktree on branch
+func (m *Manager) worktreePath(ticket, branch string) (string, error) {
+	repo, err := m.repoName()
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := m.dirTemplate()
+	if err != nil {
+		return "", err
+	}
+	if tmpl == nil {
+		return filepath.Join(m.basePath, repo, ticket), nil
+	}
+
+	dirs, err := m.worktreeDirs()
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range dirs {
+		if dir.ticket == ticket {
+			return dir.path, nil
+		}
+	}
+
+	name, err := tmpl.render(ticket, branch, now())
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(m.basePath, repo, name), nil
+}
+
+// CreateOptions controls how Create sets up a worktree
+type CreateOptions struct {
+	// BaseBranch is the branch to create from; empty uses the configured default
+	BaseBranch string
+	// Existing requires the branch to already exist and checks it out
+	Existing bool
+	// NoHook skips the configured post-create hook
+	NoHook bool
+	// Branch overrides the branch name derived from the ticket and prefix
+	Branch string
+	// Name overrides the worktree's directory name, which delete, cd, and
+	// the other commands then know it by. The ticket still names the
+	// branch unless Branch is set.
+	Name string
+	// Remote is the remote to fetch the base branch from; empty uses the
+	// configured remote
+	Remote string
+	// Track sets the new branch's upstream to the remote base branch
+	Track bool
+	// FromCurrent uses the currently checked out branch as the base and
+	// skips the fetch; it cannot be combined with BaseBranch
+	FromCurrent bool
+	// BaseFromTracking uses the upstream of the currently checked out
+	// branch, such as origin/release-2, as the remote and base branch. It
+	// falls back to the usual base branch when there is no upstream.
+	BaseFromTracking bool
+	// BranchFrom starts the new branch at a commit, tag, or other ref
+	// instead of the base branch. Nothing is fetched.
+	BranchFrom string
+	// Depth limits fetching the base branch to this many commits, making
+	// the repository shallow; zero fetches the full history
+	Depth int
+	// FetchRetries is how many times fetching the base branch is retried
+	// after a transient network error
+	FetchRetries int
+	// NoFetch skips fetching the base branch, for working offline. The new
+	// branch starts at whatever remote-tracking or local base branch is
+	// already there.
+	NoFetch bool
+	// BranchOnly creates the branch from the base without a worktree, to
+	// be checked out later with Existing. The branch must not exist yet.
+	BranchOnly bool
+	// TrackRemote creates the branch from the remote's branch of the same
+	// name, fetched first, and tracks it. Without it the branch starts at
+	// the base even when the remote has a branch of the same name.
+	TrackRemote bool
+	// CopyAll copies every copy_on_create match, including files tracked by
+	// git, instead of only the ignored ones the checkout doesn't bring
+	CopyAll bool
+}
+
+// CreateResult describes the worktree made for a ticket. When creation
+// fails it holds what was known by then, such as the path of a worktree
+// that was kept.
+type CreateResult struct {
+	Ticket string `json:"ticket"`
+	Path   string `json:"path,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	// Base is the ref the new branch started at; empty when an existing
+	// branch was checked out
+	Base string `json:"base,omitempty"`
+	// Err is the failure recorded by CreateAll, encoded as "error"
+	Err error `json:"-"`
+}
+
+// MarshalJSON encodes the result with Err as an "error" string field
+func (r CreateResult) MarshalJSON() ([]byte, error) {
+	type result CreateResult
+	encoded := struct {
+		result
+		Error string `json:"error,omitempty"`
+	}{result: result(r)}
+	if r.Err != nil {
+		encoded.Error = r.Err.Error()
+	}
+	return json.Marshal(encoded)
+}
+
+// Create creates a new git worktree. If the ticket's branch already exists
+// locally it is checked out rather than created.
+func (m *Manager) Create(ticket string, opts CreateOptions) (CreateResult, error) {
+	result := CreateResult{Ticket: ticket}
+	err := m.create(&result, opts, m.baseFetcher(opts))
+	return result, err
+}
+
+// CreateAll creates a worktree for each ticket from the same base branch,
+// which is fetched only once. A failure for one ticket does not stop the
+// others; each outcome is returned in order. With several tickets, each
+// line of progress and hook output starts with the ticket in brackets.
+func (m *Manager) CreateAll(tickets []string, opts CreateOptions) ([]CreateResult, error) {
+	if opts.Branch != "" && len(tickets) > 1 {
+		return nil, errors.New("--branch cannot be used when creating several worktrees")
+	}
+	if opts.Name != "" && len(tickets) > 1 {
+		return nil, errors.New("--name cannot be used when creating several worktrees")
+	}
+
+	fetch := m.baseFetcher(opts)
+	out, errOut := m.out, m.errOut
+	defer func() { m.out, m.errOut = out, errOut }()
+
+	results := make([]CreateResult, 0, len(tickets))
+	for _, ticket := range tickets {
+		if len(tickets) > 1 {
+			prefix := "[" + ticket + "] "
+			m.out, m.errOut = util.NewPrefixWriter(out, prefix), util.NewPrefixWriter(errOut, prefix)
+		}
+		result := CreateResult{Ticket: ticket}
+		result.Err = m.create(&result, opts, fetch)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// baseFetcher returns a function that fetches a base branch the first
+// time it is called and does nothing afterwards, or nothing at all with
+// NoFetch
+func (m *Manager) baseFetcher(opts CreateOptions) func(remote, baseBranch string) error {
+	if opts.NoFetch {
+		return func(remote, baseBranch string) error { return nil }
+	}
+	fetched := false
+	return func(remote, baseBranch string) error {
+		if fetched {
+			return nil
+		}
+		fetched = true
+		return m.fetchBranch(remote, baseBranch, opts)
+	}
+}
+
+// fetchBranch fetches branch from remote with the depth and retries of
+// opts. A failure is only a warning, since local-only repos have no
+// remote, unless it was cancelled.
+func (m *Manager) fetchBranch(remote, branch string, opts CreateOptions) error {
+	spinner := util.NewSpinner(m.out, fmt.Sprintf("Fetching latest from %s/%s...", remote, branch), m.animate)
+	spinner.Start()
+	err := m.git.FetchBranch(m.ctx, remote, branch, FetchOptions{Depth: opts.Depth, Retries: opts.FetchRetries})
+	spinner.Stop()
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		m.warnf("couldn't fetch latest from remote (this is okay for local-only repos): %v\n", err)
+	}
+	return nil
+}
+
+// create creates the worktree for result.Ticket, fetching the base branch
+// through fetch and filling in result as it goes. With a Name, result.Ticket
+// becomes the name.
+func (m *Manager) create(result *CreateResult, opts CreateOptions, fetch func(remote, baseBranch string) error) error {
+	ticket := result.Ticket
+	if err := validateTicket(ticket); err != nil {
+		return err
+	}
+	if err := validateTicketPattern(ticket, m.config.TicketPattern); err != nil {
+		return err
+	}
+	// branchTicket is what the branch is named after
+	branchTicket := ticket
+	if opts.Name != "" {
+		if opts.BranchOnly {
+			return errors.New("--name cannot be combined with --branch-only, which makes no worktree")
+		}
+		if err := validateTicket(opts.Name); err != nil {
+			return err
+		}
+		ticket = opts.Name
+		result.Ticket = ticket
+	}
+
+	if opts.FromCurrent && opts.BaseBranch != "" {
+		return errors.New("--from-current cannot be combined with a base branch")
+	}
+	if opts.BranchFrom != "" && (opts.BaseBranch != "" || opts.FromCurrent) {
+		return errors.New("--branch-from cannot be combined with a base branch or --from-current")
+	}
+	if opts.BaseFromTracking && (opts.BaseBranch != "" || opts.FromCurrent || opts.BranchFrom != "" || opts.Remote != "") {
+		return errors.New("--base-from-tracking cannot be combined with a base branch, --remote, --from-current, or --branch-from")
+	}
+	if opts.BranchFrom != "" && opts.Track {
+		return errors.New("--track cannot be combined with --branch-from")
+	}
+	if opts.BranchOnly && (opts.Existing || opts.Track) {
+		return errors.New("--branch-only cannot be combined with --existing or --track")
+	}
+	if opts.TrackRemote && (opts.BaseBranch != "" || opts.FromCurrent || opts.BaseFromTracking || opts.BranchFrom != "" ||
+		opts.Existing || opts.Track || opts.BranchOnly) {
+		return errors.New("--track-remote cannot be combined with a base branch, --from-current, --base-from-tracking, --branch-from, --existing, --track, or --branch-only")
+	}
+	if opts.Depth < 0 {
+		return fmt.Errorf("invalid --depth %d: must be a number of commits, or 0 for the full history", opts.Depth)
+	}
+	if opts.NoFetch && opts.Depth > 0 {
+		return errors.New("--depth cannot be combined with --no-fetch")
+	}
+	if opts.FetchRetries < 0 {
+		return fmt.Errorf("invalid fetch retries %d: must not be negative", opts.FetchRetries)
+	}
+
+	remote := m.config.RemoteName(opts.Remote)
+	var baseBranch string
+	// localBase starts the new branch at the local base branch as it is,
+	// without fetching
+	localBase := opts.FromCurrent
+	switch {
+	case opts.FromCurrent:
+		current, err := m.git.CurrentBranch(m.ctx)
+		if err != nil {
+			return err
+		}
+		baseBranch = current
+	case opts.BaseFromTracking:
+		if remote, baseBranch = m.trackingBase(remote); remote == "" {
+			if opts.Track {
+				return fmt.Errorf("--track needs a remote upstream, but the current branch tracks the local branch %s", baseBranch)
+			}
+			localBase = true
+		}
+	default:
+		baseBranch = m.baseBranch(opts.BaseBranch, remote)
+		if isRevision(baseBranch) {
+			// A revision such as HEAD~3 names a commit that is already
+			// here, so it is used as it is without fetching
+			if _, err := m.checkRef(baseBranch); err != nil {
+				return err
+			}
+			if opts.Track {
+				return fmt.Errorf("--track needs a remote branch as the base, not the revision %s", baseBranch)
+			}
+			localBase = true
+		} else if opts.Remote == "" {
+			// A remote-tracking base such as upstream/main is fetched
+			// from its own remote
+			if name, branch, ok := m.remoteBranch(baseBranch); ok {
+				remote, baseBranch = name, branch
+			}
+		}
+	}
+	branch := m.branchName(branchTicket, opts.Branch)
+	if opts.Branch == "" {
+		m.warnSanitized(branchTicket, branch)
+	}
+
+	if opts.BranchOnly {
+		result.Branch = branch
+		return m.createBranch(result, opts, remote, baseBranch, localBase, fetch)
+	}
+
+	worktreeDir, err := m.worktreePath(ticket, branch)
+	if err != nil {
+		return err
+	}
+	result.Path, result.Branch = worktreeDir, branch
+
+	// Ensure the base path and the repository's directory exist
+	if err := m.ensureBasePath(); err != nil {
+		return err
+	}
+	_, err = os.Stat(filepath.Dir(worktreeDir))
+	newRepoDir := errors.Is(err, fs.ErrNotExist)
+	if newRepoDir && m.dryRun != nil {
+		m.dryRunf("mkdir", "-p", filepath.Dir(worktreeDir))
+	} else if err := os.MkdirAll(filepath.Dir(worktreeDir), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Check if directory already exists
+	if _, err := os.Stat(worktreeDir); !errors.Is(err, fs.ErrNotExist) {
+		return errorf(ErrWorktreeExists, "directory already exists: %s", worktreeDir)
+	}
+
+	// Interrupting before git has finished adding the worktree leaves
+	// nothing behind that wasn't there before
+	added := false
+	var newBranch string
+	defer func() {
+		if !added && m.ctx.Err() != nil && m.dryRun == nil {
+			m.removePartialWorktree(worktreeDir, newBranch, newRepoDir)
+		}
+	}()
+
+	branchExists, err := m.git.LocalBranchExists(m.ctx, branch)
+	if err != nil {
+		return err
+	}
+	if opts.Existing && !branchExists {
+		return errorf(ErrBranchNotFound, "branch %s does not exist", branch)
+	}
+
+	if opts.BranchFrom != "" && branchExists {
+		return fmt.Errorf("branch %s already exists, --branch-from only applies to new branches", branch)
+	}
+	if opts.TrackRemote && branchExists {
+		return fmt.Errorf("branch %s already exists, --track-remote only applies to new branches", branch)
+	}
+	// The branch is looked for on the configured remote, even when the
+	// base branch comes from another one
+	branchRemote := m.config.RemoteName(opts.Remote)
+	trackRemote := false
+	if !branchExists {
+		if trackRemote, err = m.tracksRemoteBranch(opts, branchRemote, branch); err != nil {
+			return err
+		}
+	}
+
+	if branchExists {
+		// Git refuses a second checkout of a branch with a message that
+		// doesn't say where the first one is
+		checkedOutAt, err := m.git.BranchCheckedOutAt(m.ctx, branch)
+		if err != nil {
+			return err
+		}
+		if checkedOutAt != "" {
+			mainPath, err := m.mainWorktree()
+			if err != nil {
+				return err
+			}
+			if checkedOutAt == mainPath {
+				return errorf(ErrBranchCheckedOut, "branch %s is already checked out in the main working tree at %s; switch it to another branch there first, or pick another name with --branch",
+					branch, checkedOutAt)
+			}
+			return errorf(ErrBranchCheckedOut, "branch %s is already checked out in %s; switch that worktree to another branch or delete it first",
+				branch, checkedOutAt)
+		}
+
+		// Reuse the existing branch
+		m.infof("Branch %s already exists, checking it out into a new worktree...\n",
+			util.Colorize(branch, util.ColorBlue))
+		if err := m.git.AddWorktree(m.ctx, worktreeDir, branch); err != nil {
+			return fmt.Errorf("failed to create worktree: %w", err)
+		}
+	} else if trackRemote {
+		result.Base = branchRemote + "/" + branch
+		newBranch = branch
+
+		m.infof("Creating worktree for %s with new branch %s tracking %s...\n",
+			util.Colorize(ticket, util.ColorBlue), util.Colorize(branch, util.ColorBlue),
+			util.Colorize(result.Base, util.ColorBlue))
+		if err := m.git.CreateTrackingWorktree(m.ctx, worktreeDir, branch, branchRemote); err != nil {
+			return fmt.Errorf("failed to create worktree: %w", err)
+		}
+	} else {
+		startPoint, err := m.startPoint(opts, remote, baseBranch, localBase, fetch)
+		if err != nil {
+			return err
+		}
+		result.Base = startPoint
+		newBranch = branch
+
+		// Create worktree with new branch
+		m.infof("Creating worktree for %s with new branch %s from %s...\n",
+			util.Colorize(ticket, util.ColorBlue), util.Colorize(branch, util.ColorBlue),
+			util.Colorize(startPoint, util.ColorBlue))
+		if err := m.git.CreateWorktree(m.ctx, worktreeDir, branch, startPoint); err != nil {
+			return fmt.Errorf("failed to create worktree: %w", err)
+		}
+	}
+	added = true
+
+	if err := m.copyConfiguredFiles(worktreeDir, opts.CopyAll); err != nil {
+		return err
+	}
+	if err := m.applyTemplate(worktreeDir); err != nil {
+		return err
+	}
+
+	if opts.Track {
+		if err := m.git.SetUpstream(m.ctx, worktreeDir, remote, baseBranch); err != nil {
+			return fmt.Errorf("failed to set upstream to %s/%s (worktree was kept at %s): %w",
+				remote, baseBranch, worktreeDir, err)
+		}
+		m.infof("Branch %s now tracks %s\n",
+			util.Colorize(branch, util.ColorBlue), util.Colorize(remote+"/"+baseBranch, util.ColorBlue))
+	}
+
+	if m.dryRun != nil {
+		if hook := m.config.PostCreateHook; hook != "" && !opts.NoHook {
+			m.dryRunf("sh", "-c", hook)
+		}
+		m.infof("Dry run, nothing was changed\n")
+		return nil
+	}
+
+	m.updateIndex(func(worktrees map[string]IndexEntry) {
+		worktrees[ticket] = IndexEntry{Branch: branch, Base: result.Base, CreatedAt: now()}
+	})
+	m.successf("Worktree created at: %s\n", worktreeDir)
+
+	// Run the hook last; on failure the worktree is left in place
+	if hook := m.config.PostCreateHook; hook != "" && !opts.NoHook {
+		m.infof("Running post-create hook: %s\n", util.Colorize(hook, util.ColorBlue))
+		if err := runHook(hook, worktreeDir, m.out, m.errOut); err != nil {
+			return fmt.Errorf("%w (worktree was kept at %s)", err, worktreeDir)
+		}
+	}
+
+	m.infof("Run: %s to start working\n", util.Colorize("cd "+util.ShellQuote(worktreeDir), util.ColorYellow))
+	return nil
+}
+
+// removePartialWorktree cleans up after create was interrupted: a
+// worktree git had started to add is removed, or just its directory when
+// git never registered it, as is newBranch if git got as far as creating
+// it, along with the repository's directory when create made it and it is
+// empty again
+func (m *Manager) removePartialWorktree(worktreeDir, newBranch string, removeRepoDir bool) {
+	// The cleanup must run even though m.ctx is cancelled
+	ctx := context.WithoutCancel(m.ctx)
+	if _, err := os.Stat(worktreeDir); err == nil {
+		m.infof("Interrupted, removing the partly created worktree at %s\n", worktreeDir)
+		if err := m.git.RemoveWorktree(ctx, worktreeDir, true); err != nil {
+			os.RemoveAll(worktreeDir)
+		}
+	}
+	if newBranch != "" {
+		if exists, err := m.git.LocalBranchExists(ctx, newBranch); err == nil && exists {
+			m.git.DeleteBranch(ctx, newBranch)
+		}
+	}
+	if removeRepoDir {
+		// Remove fails on a directory that isn't empty, which is kept
+		os.Remove(filepath.Dir(worktreeDir))
+	}
+}
+
+// createBranch creates result.Branch without a worktree, for BranchOnly.
+// Nothing is written outside the repository, so the index and worktree
+// directory are left alone.
+func (m *Manager) createBranch(result *CreateResult, opts CreateOptions, remote, baseBranch string, localBase bool, fetch func(remote, baseBranch string) error) error {
+	branch := result.Branch
+	exists, err := m.git.LocalBranchExists(m.ctx, branch)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("branch %s already exists", branch)
+	}
+
+	startPoint, err := m.startPoint(opts, remote, baseBranch, localBase, fetch)
+	if err != nil {
+		return err
+	}
+	result.Base = startPoint
+
+	m.infof("Creating branch %s from %s...\n",
+		util.Colorize(branch, util.ColorBlue), util.Colorize(startPoint, util.ColorBlue))
+	if err := m.git.CreateBranch(m.ctx, branch, startPoint); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+	if m.dryRun != nil {
+		m.infof("Dry run, nothing was changed\n")
+		return nil
+	}
+
+	later := "go-worktree create " + util.ShellQuote(result.Ticket)
+	if opts.Branch != "" {
+		later += " --branch " + util.ShellQuote(branch)
+	}
+	m.successf("Branch %s created\n", branch)
+	m.infof("Run: %s to check it out into a worktree\n", util.Colorize(later+" --existing", util.ColorYellow))
+	return nil
+}
+
+// startPoint returns the ref a new branch starts at: BranchFrom when set,
+// the local base branch with localBase, and otherwise the base branch as
+// just fetched from remote
+func (m *Manager) startPoint(opts CreateOptions, remote, baseBranch string, localBase bool, fetch func(remote, baseBranch string) error) (string, error) {
+	switch {
+	case opts.BranchFrom != "":
+		return m.checkRef(opts.BranchFrom)
+	case localBase:
+		// The base branch is used as it is checked out locally
+		return baseBranch, nil
+	default:
+		if err := fetch(remote, baseBranch); err != nil {
+			return "", err
+		}
+		return m.baseRef(remote, baseBranch)
+	}
+}
+
+// tracksRemoteBranch reports whether the new branch is to be created from
+// and track the remote's branch of the same name, which only happens when
+// TrackRemote asks for it. The remote branch is fetched first and has to
+// exist.
+func (m *Manager) tracksRemoteBranch(opts CreateOptions, remote, branch string) (bool, error) {
+	if !opts.TrackRemote {
+		return false, nil
+	}
+	if !opts.NoFetch {
+		if err := m.fetchBranch(remote, branch, opts); err != nil {
+			return false, err
+		}
+	}
+	exists, err := m.git.RemoteBranchExists(m.ctx, remote, branch)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, errorf(ErrBranchNotFound, "remote branch %s/%s not found", remote, branch)
+	}
+	return true, nil
+}
+
+// baseBranch returns the base branch to use, preferring base, then the
+// configured default, then the default branch of remote, and main when
+// the remote's default is unknown
+func (m *Manager) baseBranch(base, remote string) string {
+	if base != "" || m.config.DefaultBaseBranch != "" {
+		return m.config.BaseBranch(base)
+	}
+	if branch, err := m.git.DefaultBranch(m.ctx, remote); err == nil {
+		return branch
+	}
+	return config.DefaultBaseBranch
+}
+
+// trackingBase returns the remote and branch that the current branch's
+// upstream points to, with an empty remote for an upstream that is a local
+// branch. Without an upstream it warns and falls back to remote and the
+// usual base branch.
+func (m *Manager) trackingBase(remote string) (string, string) {
+	reason := "HEAD is detached"
+	if current, err := m.git.CurrentBranch(m.ctx); err == nil {
+		reason = fmt.Sprintf("branch %s has no upstream", current)
+		if upstream, err := m.git.UpstreamOf(m.ctx, current); err == nil {
+			if name, branch, ok := m.remoteBranch(upstream); ok {
+				return name, branch
+			}
+			return "", upstream
+		}
+	}
+
+	baseBranch := m.baseBranch("", remote)
+	m.warnf("%s, using %s/%s as the base instead\n", reason, remote, baseBranch)
+	return remote, baseBranch
+}
+
+// remoteBranch splits a remote-tracking branch abbreviated to
+// REMOTE/BRANCH into its remote and branch. It reports false for a local
+// branch, whose name can contain slashes too, as the part before the first
+// slash is then not a configured remote.
+func (m *Manager) remoteBranch(ref string) (string, string, bool) {
+	name, branch, ok := strings.Cut(ref, "/")
+	if !ok || name == "" || branch == "" {
+		return "", "", false
+	}
+	if _, err := m.git.RemoteURL(m.ctx, name); err != nil {
+		return "", "", false
+	}
+	return name, branch, true
+}
+
+// isRevision reports whether ref is a revision expression rather than a
+// branch name: one with characters or sequences branch names can't have,
+// as in HEAD~3, main^2, or main@{1}, or a symbolic ref such as HEAD, @, or
+// FETCH_HEAD
+func isRevision(ref string) bool {
+	if strings.ContainsAny(ref, "~^:") || strings.Contains(ref, "@{") || ref == "@" {
+		return true
+	}
+	return strings.HasSuffix(ref, "HEAD") && ref == strings.ToUpper(ref)
+}
+
+// baseRef returns the ref a new branch starts at: the just-fetched
+// remote-tracking branch of remote when there is one, otherwise the local
+// baseBranch. A typo fails with a clear message instead of git's.
+func (m *Manager) baseRef(remote, baseBranch string) (string, error) {
+	for _, ref := range []string{remote + "/" + baseBranch, baseBranch} {
+		exists, err := m.git.BranchExists(m.ctx, ref)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return ref, nil
+		}
+	}
+	return "", errorf(ErrBranchNotFound, "base branch '%s' not found locally or on remote", baseBranch)
+}
+
+// checkRef returns ref unless it does not name a commit
+func (m *Manager) checkRef(ref string) (string, error) {
+	exists, err := m.git.BranchExists(m.ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", errorf(ErrBranchNotFound, "ref '%s' not found, expected a branch, tag, or commit", ref)
+	}
+	return ref, nil
+}
+
+// copyConfiguredFiles copies the copy_on_create files from the repository
+// root into a newly created worktree. Only files git ignores are copied,
+// since tracked ones come with the checkout, unless copyAll is set.
+func (m *Manager) copyConfiguredFiles(worktreeDir string, copyAll bool) error {
+	if len(m.config.CopyOnCreate) == 0 {
+		return nil
+	}
+
+	// A bare repository has no working tree to copy from
+	bare, err := m.git.IsBareRepo(m.ctx)
+	if err != nil {
+		return err
+	}
+	if bare {
+		m.warnf("skipping copy_on_create in a bare repository\n")
+		return nil
+	}
+
+	root, err := m.git.Toplevel(m.ctx)
+	if err != nil {
+		return err
+	}
+
+	matches, err := matchPatterns(root, m.config.CopyOnCreate)
+	if err != nil {
+		return err
+	}
+	if !copyAll {
+		var skipped []string
+		matches, skipped, err = ignoredPaths(root, matches, func(paths []string) (map[string]bool, error) {
+			return m.git.IgnoredPaths(m.ctx, paths)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to check copy_on_create files: %w", err)
+		}
+		for _, rel := range skipped {
+			m.infof("Skipping %s, which git doesn't ignore (use --copy-all to copy it)\n",
+				util.Colorize(rel, util.ColorBlue))
+		}
+	}
+
+	if m.dryRun != nil {
+		for _, rel := range matches {
+			m.dryRunf("cp", "-R", filepath.Join(root, rel), filepath.Join(worktreeDir, rel))
+		}
+		return nil
+	}
+
+	copied, err := copyPaths(root, worktreeDir, matches)
+	for _, rel := range copied {
+		m.infof("Copied %s\n", util.Colorize(rel, util.ColorBlue))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy files into worktree: %w", err)
+	}
+	return nil
+}
+
+// applyTemplate copies the contents of the template_dir into a newly
+// created worktree. Files already there, whether checked out or copied by
+// copy_on_create, are kept and reported as conflicts.
+func (m *Manager) applyTemplate(worktreeDir string) error {
+	if m.config.TemplateDir == "" {
+		return nil
+	}
+	templateDir, err := absPath(m.config.TemplateDir)
+	if err != nil {
+		return err
+	}
+	if !isDir(templateDir) {
+		m.warnf("template_dir %s is not a directory, skipping it\n", util.Colorize(templateDir, util.ColorBlue))
+		return nil
+	}
+
+	if m.dryRun != nil {
+		m.dryRunf("cp", "-R", "-n", templateDir+string(filepath.Separator)+".", worktreeDir)
+		return nil
+	}
+
+	copied, conflicts, err := copyTemplate(templateDir, worktreeDir)
+	if len(copied) > 0 {
+		m.infof("Copied %d file%s from template %s\n",
+			len(copied), plural(len(copied), "", "s"), util.Colorize(templateDir, util.ColorBlue))
+	}
+	for _, rel := range conflicts {
+		m.warnf("%s already exists in the worktree, not copied from the template\n", util.Colorize(rel, util.ColorBlue))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy template into worktree: %w", err)
+	}
+	return nil
+}
+
+// DeleteOptions controls how Delete removes a worktree
+type DeleteOptions struct {
+	// DeleteBranch also deletes the ticket's branch
+	DeleteBranch bool
+	// Force removes the worktree even if it has uncommitted changes
+	Force bool
+	// ForceProtected allows DeleteBranch to delete a protected branch
+	ForceProtected bool
+	// KeepDir unregisters the worktree from git but keeps its files,
+	// moving the directory under KeptDir in the base path
+	KeepDir bool
+}
+
+// KeptDir is the directory under the base path that Delete moves worktrees
+// kept with KeepDir to, one subdirectory per repository
+const KeptDir = ".kept"
+
+// Delete deletes a git worktree
+func (m *Manager) Delete(ticket string, opts DeleteOptions) error {
+	if err := validateTicket(ticket); err != nil {
+		return err
+	}
+
+	ticket, err := m.ResolveTicket(ticket)
+	if err != nil {
+		return err
+	}
+
+	worktreePath, err := m.ticketPath(ticket)
+	if err != nil {
+		return err
+	}
+
+	// Check if worktree exists
+	if _, err := os.Stat(worktreePath); errors.Is(err, fs.ErrNotExist) {
+		return errorf(ErrWorktreeNotFound, "worktree for ticket %s not found", ticket)
+	}
+
+	// Look up the checked out branch before the worktree is unregistered
+	var branch string
+	if opts.DeleteBranch {
+		worktreeMap, err := m.registeredWorktrees()
+		if err != nil {
+			return err
+		}
+		branch = m.worktreeBranch(worktreeMap, worktreePath, ticket)
+	}
+
+	if m.dryRun == nil {
+		if err := m.leaveWorktree(worktreePath); err != nil {
+			return err
+		}
+	}
+
+	// Remove worktree
+	var keptPath string
+	if opts.KeepDir {
+		if keptPath, err = m.keepWorktree(ticket, worktreePath); err != nil {
+			return err
+		}
+	} else {
+		m.infof("Removing worktree for %s...\n", util.Colorize(ticket, util.ColorBlue))
+		if err := m.git.RemoveWorktree(m.ctx, worktreePath, opts.Force); err != nil {
+			if !opts.Force && git.IsDirtyWorktreeError(err) {
+				return errorf(ErrWorktreeDirty, "worktree for ticket %s has uncommitted changes, use -f to remove it anyway", ticket)
+			}
+			if git.IsLockedWorktreeError(err) {
+				return errorf(ErrWorktreeLocked, "worktree for ticket %s is locked, unlock it first with: go-worktree unlock %s", ticket, ticket)
+			}
+			return fmt.Errorf("failed to remove worktree: %w", err)
+		}
+	}
+
+	// Delete branch if requested, keeping protected branches unless forced
+	if opts.DeleteBranch && m.config.IsProtected(branch) && !opts.ForceProtected {
+		m.warnf("refusing to delete protected branch %s (use --force-protected to delete it)\n",
+			util.Colorize(branch, util.ColorBlue))
+	} else if opts.DeleteBranch {
+		m.infof("Deleting branch %s...\n", util.Colorize(branch, util.ColorBlue))
+		if err := m.git.DeleteBranch(m.ctx, branch); err != nil {
+			return fmt.Errorf("failed to delete branch: %w", err)
+		}
+	}
+
+	if m.dryRun != nil {
+		m.infof("Dry run, nothing was changed\n")
+		return nil
+	}
+	m.updateIndex(func(worktrees map[string]IndexEntry) { delete(worktrees, ticket) })
+	if keptPath != "" {
+		m.successf("Worktree for ticket %s has been unregistered, its files were kept in %s\n",
+			ticket, util.Colorize(keptPath, util.ColorBlue))
+		return nil
+	}
+	m.successf("Worktree for ticket %s has been removed\n", ticket)
+	return nil
+}
+
+// keepWorktree unregisters the worktree at path from git without deleting
+// its files and returns where they were kept. The directory is moved out of
+// the repository's worktree directory first, since git worktree remove
+// deletes whatever it finds, and its .git file is dropped because it would
+// point at the removed registration. Uncommitted changes are kept too.
+func (m *Manager) keepWorktree(ticket, path string) (string, error) {
+	repo, err := m.repoName()
+	if err != nil {
+		return "", err
+	}
+	keptPath := filepath.Join(m.basePath, KeptDir, repo, filepath.Base(path))
+	if _, err := os.Stat(keptPath); !errors.Is(err, fs.ErrNotExist) {
+		return "", errorf(ErrWorktreeExists, "cannot keep worktree for ticket %s, %s already exists", ticket, keptPath)
+	}
+
+	m.infof("Unregistering worktree for %s and keeping its files...\n", util.Colorize(ticket, util.ColorBlue))
+	if m.dryRun != nil {
+		m.dryRunf("mkdir", "-p", filepath.Dir(keptPath))
+		m.dryRunf("mv", path, keptPath)
+		if err := m.git.RemoveWorktree(m.ctx, path, true); err != nil {
+			return "", fmt.Errorf("failed to unregister worktree: %w", err)
+		}
+		m.dryRunf("rm", filepath.Join(keptPath, ".git"))
+		return keptPath, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(keptPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(path, keptPath); err != nil {
+		return "", fmt.Errorf("failed to move worktree: %w", err)
+	}
+	if err := m.git.RemoveWorktree(m.ctx, path, true); err != nil {
+		// Put the directory back so the worktree keeps working
+		if restoreErr := os.Rename(keptPath, path); restoreErr != nil {
+			return "", fmt.Errorf("failed to unregister worktree: %w (its files are in %s)", err, keptPath)
+		}
+		if git.IsLockedWorktreeError(err) {
+			return "", errorf(ErrWorktreeLocked, "worktree for ticket %s is locked, unlock it first with: go-worktree unlock %s", ticket, ticket)
+		}
+		return "", fmt.Errorf("failed to unregister worktree: %w", err)
+	}
+	if err := os.Remove(filepath.Join(keptPath, ".git")); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to remove .git file from %s: %w", keptPath, err)
+	}
+	return keptPath, nil
+}
+
+// leaveWorktree changes to the main worktree when the current directory is
+// inside worktreePath, so git still has a working directory once it is
+// removed
+func (m *Manager) leaveWorktree(worktreePath string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if ticket, ok := ticketAt(filepath.Dir(worktreePath), wd); !ok || ticket != filepath.Base(worktreePath) {
+		return nil
+	}
+
+	mainPath, err := m.mainWorktree()
+	if err != nil || mainPath == "" {
+		return err
+	}
+	if err := os.Chdir(mainPath); err != nil {
+		return fmt.Errorf("failed to leave worktree: %w", err)
+	}
+	m.infof("Leaving the worktree being removed, run: %s\n", util.Colorize("cd "+util.ShellQuote(mainPath), util.ColorYellow))
+	return nil
+}
+
+// mainWorktree returns the path of the repository's main working tree, or
+// "" when git lists no worktrees
+func (m *Manager) mainWorktree() (string, error) {
+	worktrees, err := m.git.ListWorktrees(m.ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	if len(worktrees) == 0 {
+		return "", nil
+	}
+	// git lists the main worktree first
+	return worktrees[0].Path, nil
+}
+
+// DeleteResult records the outcome of removing one worktree in DeleteAll
+// or DeleteTickets
+type DeleteResult struct {
+	Ticket string `json:"ticket"`
+	// Path is the worktree's directory, only filled in by DeleteTickets
+	// and empty when the ticket matched no worktree
+	Path string `json:"path,omitempty"`
+	// Err is the failure, encoded as "error"
+	Err error `json:"-"`
+}
+
+// MarshalJSON encodes the result with Err as an "error" string field
+func (r DeleteResult) MarshalJSON() ([]byte, error) {
+	type result DeleteResult
+	encoded := struct {
+		result
+		Error string `json:"error,omitempty"`
+	}{result: result(r)}
+	if r.Err != nil {
+		encoded.Error = r.Err.Error()
+	}
+	return json.Marshal(encoded)
+}
+
+// DeleteTickets removes the worktree of each ticket, which may be partial
+// or "@" as with Delete. Every ticket is resolved before anything is
+// removed, so a partial ID can't end up matching a different worktree
+// once another is gone, and a worktree named twice is removed once. A
+// failure for one ticket does not stop the others; each outcome is
+// returned in order. With several tickets, each line of progress and hook
+// output starts with the ticket in brackets.
+func (m *Manager) DeleteTickets(tickets []string, opts DeleteOptions) []DeleteResult {
+	results := make([]DeleteResult, 0, len(tickets))
+	seen := make(map[string]bool)
+	for _, query := range tickets {
+		result := DeleteResult{Ticket: query}
+		if result.Err = validateTicket(query); result.Err == nil {
+			result.Ticket, result.Err = m.ResolveTicket(query)
+		}
+		if result.Err == nil {
+			if seen[result.Ticket] {
+				continue
+			}
+			seen[result.Ticket] = true
+			result.Path, result.Err = m.ExistingPath(result.Ticket)
+		}
+		if result.Err != nil && result.Ticket == "" {
+			result.Ticket = query
+		}
+		results = append(results, result)
+	}
+
+	out, errOut := m.out, m.errOut
+	defer func() { m.out, m.errOut = out, errOut }()
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+		if len(results) > 1 {
+			prefix := "[" + results[i].Ticket + "] "
+			m.out, m.errOut = util.NewPrefixWriter(out, prefix), util.NewPrefixWriter(errOut, prefix)
+		}
+		results[i].Err = m.Delete(results[i].Ticket, opts)
+	}
+	return results
+}
+
+// DeleteAll removes every managed worktree for the current repository,
+// and optionally their branches. A failure for one worktree does not stop
+// the others; each outcome is returned in order.
+func (m *Manager) DeleteAll(deleteBranches bool) ([]DeleteResult, error) {
+	tickets, err := m.Tickets()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]DeleteResult, 0, len(tickets))
+	for _, ticket := range tickets {
+		err := m.Delete(ticket, DeleteOptions{DeleteBranch: deleteBranches})
+		results = append(results, DeleteResult{Ticket: ticket, Err: err})
+	}
+	return results, nil
+}
+
+// registeredWorktrees returns a map of registered worktree paths to branches
+func (m *Manager) registeredWorktrees() (map[string]string, error) {
+	worktrees, err := m.git.ListWorktrees(m.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	worktreeMap := make(map[string]string) // path -> branch
+	for _, wt := range worktrees {
+		branch := wt.Branch
+		if wt.Detached {
+			branch = "detached"
+		}
+		worktreeMap[wt.Path] = branch
+	}
+	return worktreeMap, nil
+}
+
+// lookupPath returns the value stored for path in a map keyed by the paths
+// git reports, resolving symlinks so paths under a symlinked base path
+// still match
+func lookupPath[V any](byPath map[string]V, path string) (V, bool) {
+	if value, ok := byPath[path]; ok {
+		return value, true
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		var zero V
+		return zero, false
+	}
+	value, ok := byPath[resolved]
+	return value, ok
+}
+
+// Prune removes the directories under the repo's worktree root left behind
+// by linked worktrees git no longer knows about, and returns their paths. A
+// directory only counts as stale when its .git file points at an
+// administrative directory that is gone; anything else, such as a main
+// worktree, whose .git is a directory, or a plain directory, is never
+// touched. With dryRun set, nothing is deleted and the paths that would be
+// removed are returned.
+func (m *Manager) Prune(dryRun bool) ([]string, error) {
+	repo, err := m.repoName()
+	if err != nil {
+		return nil, err
+	}
+
+	worktreeMap, err := m.registeredWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	repoPath := filepath.Join(m.basePath, repo)
+	entries, err := os.ReadDir(repoPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worktree directory: %w", err)
+	}
+
+	var stale []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(repoPath, entry.Name())
+		if _, ok := lookupPath(worktreeMap, path); ok {
+			continue
+		}
+		gitDir, ok := linkedGitDir(path)
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(gitDir); !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if !dryRun {
+			m.infof("Removing %s (%s)\n", util.Colorize(entry.Name(), util.ColorYellow), path)
+			if err := os.RemoveAll(path); err != nil {
+				return stale, fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+		}
+		stale = append(stale, path)
+	}
+	return stale, nil
+}
+
+// RenderPrune writes the result of Prune, listing each path when dryRun
+// is set and a summary line otherwise
+func RenderPrune(w io.Writer, stale []string, dryRun bool) {
+	if dryRun {
+		for _, path := range stale {
+			fmt.Fprintf(w, "Would remove %s (%s)\n", util.Colorize(filepath.Base(path), util.ColorYellow), path)
+		}
+		fmt.Fprintf(w, "%d stale worktree director%s would be removed\n", len(stale), plural(len(stale), "y", "ies"))
+		return
+	}
+	util.SuccessTo(w, "Pruned %d stale worktree director%s\n", len(stale), plural(len(stale), "y", "ies"))
+}
+
+// plural returns singular when n is 1 and pluralSuffix otherwise
+func plural(n int, singular, pluralSuffix string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralSuffix
+}
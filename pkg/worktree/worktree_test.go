@@ -0,0 +1,2235 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdelgado509/go-worktree/internal/config"
+	"github.com/mdelgado509/go-worktree/internal/git"
+	"github.com/mdelgado509/go-worktree/internal/util"
+)
+
+// TestMain keeps the worktree index written by Create and List out of the
+// user's state directory
+func TestMain(m *testing.M) {
+	stateHome, err := os.MkdirTemp("", "go-worktree-state")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv(config.StateHomeEnv, stateHome)
+	code := m.Run()
+	os.RemoveAll(stateHome)
+	os.Exit(code)
+}
+
+// TestGetWorktreeBasePath tests the getWorktreeBasePath function
+func TestGetWorktreeBasePath(t *testing.T) {
+	t.Setenv(BasePathEnv, "")
+
+	path, err := getWorktreeBasePath("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	home, _ := os.UserHomeDir()
+	expected := filepath.Join(home, "worktrees")
+	if path != expected {
+		t.Errorf("Expected path %s, got %s", expected, path)
+	}
+}
+
+// TestGetWorktreeBasePathEnv tests that GO_WORKTREE_HOME overrides the default
+func TestGetWorktreeBasePathEnv(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	testCases := []struct {
+		env      string
+		expected string
+	}{
+		{"/tmp/custom-worktrees", "/tmp/custom-worktrees"},
+		{"~/src/worktrees", filepath.Join(home, "src", "worktrees")},
+		{"~", home},
+	}
+
+	for _, tc := range testCases {
+		t.Setenv(BasePathEnv, tc.env)
+		path, err := getWorktreeBasePath("")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if path != tc.expected {
+			t.Errorf("For %q expected path %s, got %s", tc.env, tc.expected, path)
+		}
+	}
+}
+
+// TestBasePathPrecedence tests that the env var beats the config file,
+// which beats the built-in default
+func TestBasePathPrecedence(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	cfg := &config.Config{BasePath: "~/configured"}
+
+	t.Setenv(BasePathEnv, "/tmp/from-env")
+	if m := newManager(cfg); m.basePath != "/tmp/from-env" {
+		t.Errorf("Expected env base path, got %s", m.basePath)
+	}
+
+	t.Setenv(BasePathEnv, "")
+	if m := newManager(cfg); m.basePath != filepath.Join(home, "configured") {
+		t.Errorf("Expected configured base path, got %s", m.basePath)
+	}
+
+	if m := newManager(&config.Config{}); m.basePath != filepath.Join(home, "worktrees") {
+		t.Errorf("Expected default base path, got %s", m.basePath)
+	}
+}
+
+// TestBranchName tests that the configured prefix is applied to branches
+func TestBranchName(t *testing.T) {
+	m := newManager(&config.Config{BranchPrefix: "feature/"})
+	if got := m.branchName("ABC-746", ""); got != "feature/ABC-746" {
+		t.Errorf("Expected feature/ABC-746, got %s", got)
+	}
+	if got := m.branchName("ABC-746", "hotfix/login"); got != "hotfix/login" {
+		t.Errorf("Expected override hotfix/login, got %s", got)
+	}
+
+	m = newManager(&config.Config{})
+	if got := m.branchName("ABC-746", ""); got != "ABC-746" {
+		t.Errorf("Expected ABC-746, got %s", got)
+	}
+}
+
+// TestWorktreeBranch tests that delete resolves the same branch create used
+func TestWorktreeBranch(t *testing.T) {
+	m := newManager(&config.Config{BranchPrefix: "feature/"})
+	path := "/tmp/wt/repo/ABC-746"
+
+	// The registered branch wins, even if it was set with --branch
+	worktreeMap := map[string]string{path: "hotfix/login"}
+	if got := m.worktreeBranch(worktreeMap, path, "ABC-746"); got != "hotfix/login" {
+		t.Errorf("Expected hotfix/login, got %s", got)
+	}
+
+	// Without git data the prefixed name is derived, matching create
+	created := m.branchName("ABC-746", "")
+	if got := m.worktreeBranch(map[string]string{}, path, "ABC-746"); got != created {
+		t.Errorf("Expected %s, got %s", created, got)
+	}
+
+	// A detached worktree also falls back to the derived name
+	worktreeMap = map[string]string{path: "detached"}
+	if got := m.worktreeBranch(worktreeMap, path, "ABC-746"); got != created {
+		t.Errorf("Expected %s, got %s", created, got)
+	}
+}
+
+// TestSetOutput tests that progress messages are discarded until an
+// output writer is set
+func TestSetOutput(t *testing.T) {
+	m := newManager(&config.Config{})
+	if m.out != io.Discard {
+		t.Errorf("Expected progress output to be discarded by default")
+	}
+
+	var buf bytes.Buffer
+	m.SetOutput(&buf)
+	m.infof("Creating %s\n", "ABC-746")
+	if buf.String() != "Creating ABC-746\n" {
+		t.Errorf("Expected %q, got %q", "Creating ABC-746\n", buf.String())
+	}
+}
+
+// TestPrune tests that Prune removes only directories whose .git file
+// points at a git directory that is gone, matching registered worktrees
+// through a symlinked base path, keeps plain directories and checkouts, and
+// removes nothing in dry-run mode
+func TestPrune(t *testing.T) {
+	testCases := []struct {
+		name      string
+		symlinked bool
+		dryRun    bool
+	}{
+		{"prune", false, false},
+		{"dry run", false, true},
+		{"symlinked base path", true, false},
+	}
+
+	for _, tc := range testCases {
+		realBase, err := filepath.EvalSymlinks(t.TempDir())
+		if err != nil {
+			t.Fatalf("%s: failed to resolve temp dir: %v", tc.name, err)
+		}
+		basePath := realBase
+		if tc.symlinked {
+			basePath = filepath.Join(t.TempDir(), "worktrees")
+			if err := os.Symlink(realBase, basePath); err != nil {
+				t.Skipf("Skipping test: symlinks unsupported: %v", err)
+			}
+		}
+
+		// git reports worktree paths with symlinks resolved
+		repoDir := filepath.Join(realBase, "test-repo")
+		g := newMockGit()
+		g.worktrees = []GitWorktree{
+			{Path: "/repo/test-repo", Branch: "main"},
+			{Path: filepath.Join(repoDir, "ABC-1"), Branch: "ABC-1"},
+		}
+		adminDir := t.TempDir()
+		gone := filepath.Join(adminDir, "gone")
+		for name, content := range map[string]string{
+			// Registered, so kept even though its git directory is missing
+			"ABC-1/.git":  "gitdir: " + gone,
+			"ABC-2/.git":  "gitdir: " + gone,
+			"ABC-3/.git":  "gitdir: " + adminDir,
+			"lib/util.go": "package lib",
+			".git/HEAD":   "ref: refs/heads/main",
+			"notes.txt":   "notes",
+		} {
+			writeFile(t, filepath.Join(repoDir, name), content)
+		}
+		m := NewManagerWithGit(g, basePath)
+
+		stale, err := m.Prune(tc.dryRun)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		expected := []string{filepath.Join(basePath, "test-repo", "ABC-2")}
+		if !reflect.DeepEqual(stale, expected) {
+			t.Errorf("%s: expected stale %v, got %v", tc.name, expected, stale)
+		}
+
+		for _, name := range []string{"ABC-1", "ABC-2", "ABC-3", "lib", ".git", "notes.txt"} {
+			_, err := os.Stat(filepath.Join(repoDir, name))
+			kept := err == nil
+			if shouldKeep := tc.dryRun || name != "ABC-2"; kept != shouldKeep {
+				t.Errorf("%s: expected %s kept to be %t, got %t", tc.name, name, shouldKeep, kept)
+			}
+		}
+		assertCalls(t, g)
+	}
+}
+
+// TestRenderPrune tests the dry-run listing and the summary line
+func TestRenderPrune(t *testing.T) {
+	defer util.SetColorEnabled(util.ColorEnabled())
+	util.SetColorEnabled(false)
+
+	stale := []string{"/tmp/wt/repo/ABC-1", "/tmp/wt/repo/ABC-2"}
+
+	var buf bytes.Buffer
+	RenderPrune(&buf, stale, true)
+	for _, want := range []string{"Would remove ABC-1 (/tmp/wt/repo/ABC-1)", "2 stale worktree directories would be removed"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected output to contain %q, got %q", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	RenderPrune(&buf, stale[:1], false)
+	if want := "Success! Pruned 1 stale worktree directory\n"; buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
+
+// mockGit is an in-memory GitClient. Worktree directories are created and
+// removed on disk so the Manager's existence checks behave as with git.
+type mockGit struct {
+	repoName      string
+	repoErr       error
+	currentBranch string
+	// remoteURL is the URL of every remote not in remoteURLs
+	remoteURL     string
+	remoteURLs    map[string]string
+	defaultBranch string
+	// upstreams maps branches to their upstream, as UpstreamOf reports it
+	upstreams     map[string]string
+	repoNameCalls int
+	bare          bool
+	// toplevel overrides the repository root Toplevel reports
+	toplevel string
+	// ignored holds the paths IsIgnored reports as ignored
+	ignored  map[string]bool
+	branches map[string]bool
+	// merged maps bases to the branches MergedBranches reports for them
+	merged    map[string][]string
+	worktrees []GitWorktree
+	fetchErr  error
+	removeErr error
+	// createErr makes CreateWorktree fail after creating the branch and
+	// directory, like git interrupted halfway
+	createErr error
+	// renameErr makes RenameBranch fail
+	renameErr error
+	// dryRun is set by SetDryRun; mutating operations are then only recorded
+	dryRun io.Writer
+	// calls records each mutating operation in order
+	calls []string
+}
+
+func newMockGit() *mockGit {
+	return &mockGit{repoName: "test-repo", branches: map[string]bool{"main": true}}
+}
+
+func (g *mockGit) record(format string, args ...any) {
+	g.calls = append(g.calls, fmt.Sprintf(format, args...))
+}
+
+func (g *mockGit) SetDryRun(w io.Writer) { g.dryRun = w }
+
+func (g *mockGit) IsInsideRepo(ctx context.Context) (bool, error) { return true, nil }
+
+func (g *mockGit) IsBareRepo(ctx context.Context) (bool, error) { return g.bare, nil }
+
+func (g *mockGit) GetRepoName(ctx context.Context) (string, error) {
+	g.repoNameCalls++
+	return g.repoName, g.repoErr
+}
+
+func (g *mockGit) GetRepoIdentifier(ctx context.Context, remote string) (string, error) {
+	remoteURL, err := g.RemoteURL(ctx, remote)
+	if err != nil {
+		return "", err
+	}
+	return git.ParseRemoteURL(remoteURL)
+}
+
+func (g *mockGit) Toplevel(ctx context.Context) (string, error) {
+	if g.toplevel != "" {
+		return g.toplevel, nil
+	}
+	return "/repo/" + g.repoName, nil
+}
+
+func (g *mockGit) CurrentBranch(ctx context.Context) (string, error) { return g.currentBranch, nil }
+
+func (g *mockGit) UpstreamOf(ctx context.Context, branch string) (string, error) {
+	if upstream, ok := g.upstreams[branch]; ok {
+		return upstream, nil
+	}
+	return "", fmt.Errorf("branch %s has no upstream", branch)
+}
+
+func (g *mockGit) FetchBranch(ctx context.Context, remote, branch string, opts FetchOptions) error {
+	call := fmt.Sprintf("fetch %s %s", remote, branch)
+	if opts.Depth > 0 {
+		call += fmt.Sprintf(" --depth %d", opts.Depth)
+	}
+	if opts.Retries > 0 {
+		call += fmt.Sprintf(" --retries %d", opts.Retries)
+	}
+	g.record("%s", call)
+	return g.fetchErr
+}
+
+func (g *mockGit) CreateWorktree(ctx context.Context, path, branchName, startPoint string) error {
+	g.record("create %s %s %s", path, branchName, startPoint)
+	g.branches[branchName] = true
+	if g.createErr != nil {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return err
+		}
+		return g.createErr
+	}
+	return g.addWorktree(path, branchName)
+}
+
+func (g *mockGit) CreateTrackingWorktree(ctx context.Context, path, branchName, remote string) error {
+	g.record("track %s %s %s/%s", path, branchName, remote, branchName)
+	g.branches[branchName] = true
+	return g.addWorktree(path, branchName)
+}
+
+func (g *mockGit) AddWorktree(ctx context.Context, path, branchName string) error {
+	g.record("add %s %s", path, branchName)
+	return g.addWorktree(path, branchName)
+}
+
+func (g *mockGit) CreateBranch(ctx context.Context, branchName, startPoint string) error {
+	g.record("branch %s %s", branchName, startPoint)
+	if g.dryRun == nil {
+		g.branches[branchName] = true
+	}
+	return nil
+}
+
+func (g *mockGit) addWorktree(path, branchName string) error {
+	if g.dryRun != nil {
+		return nil
+	}
+	g.worktrees = append(g.worktrees, GitWorktree{Path: path, Branch: branchName})
+	return os.MkdirAll(path, 0755)
+}
+
+func (g *mockGit) SetUpstream(ctx context.Context, path, remote, branch string) error {
+	g.record("upstream %s %s/%s", path, remote, branch)
+	return nil
+}
+
+func (g *mockGit) LocalBranchExists(ctx context.Context, branchName string) (bool, error) {
+	return g.branches[branchName], nil
+}
+
+func (g *mockGit) RemoteBranchExists(ctx context.Context, remote, branch string) (bool, error) {
+	return g.branches[remote+"/"+branch], nil
+}
+
+func (g *mockGit) IgnoredPaths(ctx context.Context, paths []string) (map[string]bool, error) {
+	ignored := make(map[string]bool)
+	for _, path := range paths {
+		ignored[path] = g.ignored[path]
+	}
+	return ignored, nil
+}
+
+func (g *mockGit) BranchExists(ctx context.Context, ref string) (bool, error) {
+	return g.branches[ref], nil
+}
+
+func (g *mockGit) RemoveWorktree(ctx context.Context, path string, force bool) error {
+	g.record("remove %s %t", path, force)
+	if g.removeErr != nil {
+		return g.removeErr
+	}
+	if g.dryRun != nil {
+		return nil
+	}
+	for i, wt := range g.worktrees {
+		if wt.Path == path && wt.Locked {
+			return errors.New("fatal: cannot remove a locked working tree;")
+		}
+		if wt.Path == path {
+			g.worktrees = append(g.worktrees[:i], g.worktrees[i+1:]...)
+			return os.RemoveAll(path)
+		}
+	}
+	return fmt.Errorf("fatal: '%s' is not a working tree", path)
+}
+
+func (g *mockGit) MoveWorktree(ctx context.Context, oldPath, newPath string) error {
+	g.record("move %s %s", oldPath, newPath)
+	for i, wt := range g.worktrees {
+		if wt.Path == oldPath {
+			g.worktrees[i].Path = newPath
+		}
+	}
+	return os.Rename(oldPath, newPath)
+}
+
+func (g *mockGit) LockWorktree(ctx context.Context, path, reason string) error {
+	g.record("lock %s %s", path, reason)
+	return g.setLocked(path, true, reason)
+}
+
+func (g *mockGit) UnlockWorktree(ctx context.Context, path string) error {
+	g.record("unlock %s", path)
+	return g.setLocked(path, false, "")
+}
+
+func (g *mockGit) setLocked(path string, locked bool, reason string) error {
+	for i, wt := range g.worktrees {
+		if wt.Path != path {
+			continue
+		}
+		if wt.Locked == locked {
+			return fmt.Errorf("fatal: '%s' is already locked or not locked", path)
+		}
+		g.worktrees[i].Locked, g.worktrees[i].LockReason = locked, reason
+		return nil
+	}
+	return fmt.Errorf("fatal: '%s' is not a working tree", path)
+}
+
+func (g *mockGit) RenameBranch(ctx context.Context, oldName, newName string) error {
+	g.record("rename-branch %s %s", oldName, newName)
+	return g.renameErr
+}
+
+func (g *mockGit) DeleteBranch(ctx context.Context, branchName string) error {
+	g.record("delete-branch %s", branchName)
+	delete(g.branches, branchName)
+	return nil
+}
+
+func (g *mockGit) DefaultBranch(ctx context.Context, remote string) (string, error) {
+	if g.defaultBranch == "" {
+		return "", fmt.Errorf("default branch of %s is unknown", remote)
+	}
+	return g.defaultBranch, nil
+}
+
+func (g *mockGit) RemoteURL(ctx context.Context, remote string) (string, error) {
+	if remoteURL, ok := g.remoteURLs[remote]; ok {
+		return remoteURL, nil
+	}
+	if g.remoteURL == "" {
+		return "", fmt.Errorf("no %s remote configured", remote)
+	}
+	return g.remoteURL, nil
+}
+
+func (g *mockGit) ListLocalBranches(ctx context.Context) ([]string, error) {
+	var branches []string
+	for branch := range g.branches {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+	return branches, nil
+}
+
+func (g *mockGit) MergedBranches(ctx context.Context, base string) ([]string, error) {
+	return g.merged[base], nil
+}
+
+func (g *mockGit) BranchCheckedOutAt(ctx context.Context, branch string) (string, error) {
+	for _, wt := range g.worktrees {
+		if wt.Branch == branch {
+			return wt.Path, nil
+		}
+	}
+	return "", nil
+}
+
+func (g *mockGit) ListWorktrees(ctx context.Context) ([]GitWorktree, error) { return g.worktrees, nil }
+
+func (g *mockGit) WorktreeStatus(ctx context.Context, path string) (Status, error) {
+	return Status{}, nil
+}
+
+// assertCalls fails the test unless the mock saw exactly the expected calls
+func assertCalls(t *testing.T, g *mockGit, expected ...string) {
+	t.Helper()
+	if strings.Join(g.calls, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected calls %q, got %q", expected, g.calls)
+	}
+}
+
+// TestGetPath tests the GetPath function
+func TestGetPath(t *testing.T) {
+	tempDir := t.TempDir()
+	m := NewManagerWithGit(newMockGit(), tempDir)
+
+	path, err := m.GetPath("TICKET-123")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := filepath.Join(tempDir, "test-repo", "TICKET-123")
+	if path != expected {
+		t.Errorf("Expected path %s, got %s", expected, path)
+	}
+}
+
+// TestGetPathAbsolute tests that a ~ or relative base path yields an
+// absolute, cleaned worktree path
+func TestGetPathAbsolute(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("Skipping test: no home directory")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	testCases := []struct {
+		basePath string
+		expected string
+	}{
+		{"~/worktrees", filepath.Join(home, "worktrees", "test-repo", "TICKET-123")},
+		{"~/worktrees/../wt/", filepath.Join(home, "wt", "test-repo", "TICKET-123")},
+		{"worktrees", filepath.Join(wd, "worktrees", "test-repo", "TICKET-123")},
+	}
+
+	for _, tc := range testCases {
+		path, err := NewManagerWithGit(newMockGit(), tc.basePath).GetPath("TICKET-123")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !filepath.IsAbs(path) {
+			t.Errorf("Expected absolute path for base %q, got %s", tc.basePath, path)
+		}
+		if path != tc.expected {
+			t.Errorf("Expected path %s for base %q, got %s", tc.expected, tc.basePath, path)
+		}
+	}
+}
+
+// TestListAll tests that --all adds git worktrees outside the managed
+// directory once, marked as unmanaged
+func TestListAll(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return created }
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// git lists the main checkout first
+	g.worktrees = append([]GitWorktree{{Path: "/src/test-repo", Branch: "main"}}, g.worktrees...)
+	g.worktrees = append(g.worktrees,
+		GitWorktree{Path: "/tmp/experiment", Detached: true},
+		GitWorktree{Path: "/src/test-repo.git", Bare: true})
+
+	entries, err := m.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected only the managed worktree, got %+v", entries)
+	}
+
+	entries, err = m.List(ListOptions{All: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []Entry{
+		{Ticket: "ABC-746", Path: filepath.Join(m.basePath, "test-repo", "ABC-746"), Branch: "ABC-746", Base: "main", CreatedAt: &created},
+		{Ticket: "test-repo", Path: "/src/test-repo", Branch: "main", Unmanaged: true, Main: true},
+		{Ticket: "experiment", Path: "/tmp/experiment", Branch: "detached", Unmanaged: true},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entries)
+	}
+}
+
+// TestListStale tests that --stale marks the worktrees whose branch is
+// merged into the remote's default branch, but not the base branch itself
+// or detached worktrees
+func TestListStale(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	for _, ticket := range []string{"ABC-1", "ABC-2", "ABC-3", "main"} {
+		if _, err := m.Create(ticket, CreateOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	g.worktrees[2].Detached = true
+	g.branches["origin/main"] = true
+	g.merged = map[string][]string{"origin/main": {"ABC-1", "ABC-3", "main"}}
+
+	entries, err := m.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.Merged {
+			t.Errorf("Expected no worktree marked merged without Stale, got %s", entry.Ticket)
+		}
+	}
+
+	entries, err = m.List(ListOptions{Stale: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	merged := map[string]bool{}
+	for _, entry := range entries {
+		merged[entry.Ticket] = entry.Merged
+	}
+	expected := map[string]bool{"ABC-1": true, "ABC-2": false, "ABC-3": false, "main": false}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected %v, got %v", expected, merged)
+	}
+}
+
+// TestListSort tests ordering the listing by each sort key, reversed or
+// not, and rejecting unknown keys
+func TestListSort(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	now := time.Now()
+	worktrees := []struct {
+		ticket string
+		branch string
+		size   int
+		age    time.Duration
+	}{
+		{"ABC-2", "feature/b", 10, time.Hour},
+		{"ABC-1", "feature/c", 300, 3 * time.Hour},
+		{"ABC-3", "feature/a", 2000, time.Minute},
+	}
+	for _, wt := range worktrees {
+		if _, err := m.Create(wt.ticket, CreateOptions{Branch: wt.branch}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		path, _ := m.ticketPath(wt.ticket)
+		writeFile(t, filepath.Join(path, "data"), strings.Repeat("x", wt.size))
+		if err := os.Chtimes(path, now, now.Add(-wt.age)); err != nil {
+			t.Fatalf("Failed to set mtime: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		sort     string
+		reverse  bool
+		expected string
+	}{
+		{"", false, "ABC-1,ABC-2,ABC-3"},
+		{SortName, true, "ABC-3,ABC-2,ABC-1"},
+		{SortBranch, false, "ABC-3,ABC-2,ABC-1"},
+		{SortMtime, false, "ABC-3,ABC-2,ABC-1"},
+		{SortMtime, true, "ABC-1,ABC-2,ABC-3"},
+		{SortSize, false, "ABC-3,ABC-1,ABC-2"},
+	}
+	for _, tc := range testCases {
+		entries, err := m.List(ListOptions{Sort: tc.sort, Reverse: tc.reverse})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var tickets []string
+		for _, entry := range entries {
+			tickets = append(tickets, entry.Ticket)
+		}
+		if got := strings.Join(tickets, ","); got != tc.expected {
+			t.Errorf("Sort %q reverse %v: expected %s, got %s", tc.sort, tc.reverse, tc.expected, got)
+		}
+	}
+
+	if _, err := m.List(ListOptions{Sort: "age"}); err == nil || !strings.Contains(err.Error(), "invalid sort") {
+		t.Errorf("Expected an invalid sort error, got %v", err)
+	}
+}
+
+// TestListUnreadable tests that a worktree directory that can't be read is
+// listed with its error and fails the listing as a whole
+func TestListUnreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("Skipping test: root can read any directory")
+	}
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	for _, ticket := range []string{"ABC-1", "ABC-2"} {
+		if _, err := m.Create(ticket, CreateOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC-1")
+	if err := os.Chmod(path, 0); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+	defer os.Chmod(path, 0755)
+
+	entries, err := m.List(ListOptions{Size: true})
+	var listErr *ListError
+	if !errors.As(err, &listErr) || !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("Expected a ListError for the permission failure, got %v", err)
+	}
+	if len(entries) != 2 || !errors.Is(entries[0].Err, fs.ErrPermission) || entries[1].Err != nil {
+		t.Errorf("Expected both worktrees with only ABC-1 failing, got %+v", entries)
+	}
+	if len(listErr.Entries) != 1 || listErr.Entries[0].Ticket != "ABC-1" {
+		t.Errorf("Expected only ABC-1 in the error, got %+v", listErr.Entries)
+	}
+}
+
+// TestListPathWithSpaces tests that worktrees with spaces in the base path
+// and ticket are found with their branches, and that the repository's
+// directory gets dashes for spaces
+func TestListPathWithSpaces(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return created }
+	g := newMockGit()
+	g.repoName = "My Repo"
+	m := NewManagerWithGit(g, filepath.Join(t.TempDir(), "my worktrees"))
+	if _, err := m.Create("ABC 746", CreateOptions{Branch: "feature/ABC-746"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	entries, err := m.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []Entry{{
+		Ticket:    "ABC 746",
+		Path:      filepath.Join(m.basePath, "My-Repo", "ABC 746"),
+		Branch:    "feature/ABC-746",
+		Base:      "main",
+		CreatedAt: &created,
+	}}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entries)
+	}
+}
+
+// TestCreate tests that a new branch is fetched and created from the base
+func TestCreate(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected worktree directory %s: %v", path, err)
+	}
+	assertCalls(t, g,
+		"fetch origin main",
+		"create "+path+" ABC-746 main")
+}
+
+// TestCreateBasePath tests that the first create makes a missing base
+// path and reports it
+func TestCreateBasePath(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, filepath.Join(t.TempDir(), "nested", "worktrees"))
+
+	var buf bytes.Buffer
+	m.SetOutput(&buf)
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info, err := os.Stat(m.basePath); err != nil || !info.IsDir() {
+		t.Fatalf("Expected base path %s to be created: %v", m.basePath, err)
+	}
+	if !strings.Contains(buf.String(), "Created worktree base path "+m.basePath) {
+		t.Errorf("Expected base path to be reported, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := m.Create("ABC-747", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "Created worktree base path") {
+		t.Errorf("Expected an existing base path not to be reported, got %q", buf.String())
+	}
+}
+
+// TestCreateDryRun tests that a dry run reports the directories and hook
+// it would create and run without touching the filesystem
+func TestCreateDryRun(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, filepath.Join(t.TempDir(), "worktrees"))
+	m.config.PostCreateHook = "touch hooked"
+
+	var buf bytes.Buffer
+	m.SetOutput(&buf)
+	m.SetDryRun(&buf)
+	if g.dryRun != &buf {
+		t.Fatalf("Expected dry-run mode to reach the git client")
+	}
+
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(m.basePath); !os.IsNotExist(err) {
+		t.Errorf("Expected base path %s not to be created", m.basePath)
+	}
+
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	assertCalls(t, g,
+		"fetch origin main",
+		"create "+path+" ABC-746 main")
+	for _, want := range []string{
+		"[dry-run] mkdir -p " + m.basePath + "\n",
+		"[dry-run] mkdir -p " + filepath.Dir(path) + "\n",
+		"[dry-run] sh -c 'touch hooked'\n",
+		"Dry run, nothing was changed",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected output to contain %q, got %q", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "Success!") {
+		t.Errorf("Expected no success message in a dry run, got %q", buf.String())
+	}
+}
+
+// TestCreateResult tests the returned description of a new worktree and
+// its JSON encoding, with and without an error
+func TestCreateResult(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	result, err := m.Create("ABC-746", CreateOptions{Branch: "feature/login"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := CreateResult{
+		Ticket: "ABC-746",
+		Path:   filepath.Join(m.basePath, "test-repo", "ABC-746"),
+		Branch: "feature/login",
+		Base:   "main",
+	}
+	if result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := fmt.Sprintf(`{"ticket":"ABC-746","path":%q,"branch":"feature/login","base":"main"}`, expected.Path)
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	data, err = json.Marshal(CreateResult{Ticket: "ABC-1", Err: errors.New("boom")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := `{"ticket":"ABC-1","error":"boom"}`; string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+}
+
+// TestCreateAll tests creating several worktrees with a single fetch,
+// continuing past a ticket that fails
+func TestCreateAll(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-2", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	g.calls = nil
+
+	results, err := m.CreateAll([]string{"ABC-1", "ABC-2", "ABC-3"}, CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %+v", results)
+	}
+	for i, ticket := range []string{"ABC-1", "ABC-2", "ABC-3"} {
+		if results[i].Ticket != ticket {
+			t.Errorf("Expected result %d for %s, got %s", i, ticket, results[i].Ticket)
+		}
+		if failed := results[i].Err != nil; failed != (ticket == "ABC-2") {
+			t.Errorf("Unexpected outcome for %s: %v", ticket, results[i].Err)
+		}
+	}
+	if !errors.Is(results[1].Err, ErrWorktreeExists) {
+		t.Errorf("Expected ErrWorktreeExists for ABC-2, got %v", results[1].Err)
+	}
+
+	repoPath := filepath.Join(m.basePath, "test-repo")
+	assertCalls(t, g,
+		"fetch origin main",
+		"create "+filepath.Join(repoPath, "ABC-1")+" ABC-1 main",
+		"create "+filepath.Join(repoPath, "ABC-3")+" ABC-3 main")
+}
+
+// TestCreateAllPrefix tests that progress and hook output is prefixed
+// with the ticket when creating several worktrees
+func TestCreateAllPrefix(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	m.config.PostCreateHook = "echo installed; echo warning >&2"
+	var out, errOut bytes.Buffer
+	m.SetOutput(&out)
+	m.errOut = &errOut
+
+	if _, err := m.CreateAll([]string{"ABC-1", "ABC-2"}, CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		if !strings.HasPrefix(line, "[ABC-1] ") && !strings.HasPrefix(line, "[ABC-2] ") {
+			t.Errorf("Expected a ticket prefix, got %q", line)
+		}
+	}
+	for _, want := range []string{"[ABC-1] installed\n", "[ABC-2] installed\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, got %q", want, out.String())
+		}
+	}
+	if want := "[ABC-1] warning\n[ABC-2] warning\n"; errOut.String() != want {
+		t.Errorf("Expected %q, got %q", want, errOut.String())
+	}
+
+	// A single ticket and later calls are not prefixed
+	out.Reset()
+	if _, err := m.CreateAll([]string{"ABC-3"}, CreateOptions{NoHook: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(out.String(), "[ABC-") {
+		t.Errorf("Expected no prefix for a single ticket, got %q", out.String())
+	}
+}
+
+// TestCreateAllBranch tests that one branch name can't be shared by
+// several worktrees
+func TestCreateAllBranch(t *testing.T) {
+	m := NewManagerWithGit(newMockGit(), t.TempDir())
+	if _, err := m.CreateAll([]string{"ABC-1", "ABC-2"}, CreateOptions{Branch: "feature"}); err == nil {
+		t.Errorf("Expected error for --branch with several tickets")
+	}
+}
+
+// TestCreateFetchFailure tests that a failed fetch does not stop creation
+func TestCreateFetchFailure(t *testing.T) {
+	g := newMockGit()
+	g.fetchErr = errors.New("no remote")
+	m := NewManagerWithGit(g, t.TempDir())
+
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !g.branches["ABC-746"] {
+		t.Errorf("Expected branch ABC-746 to be created")
+	}
+}
+
+// TestCreateCancelled tests that cancelling during the fetch aborts
+// instead of creating the worktree from stale refs
+func TestCreateCancelled(t *testing.T) {
+	g := newMockGit()
+	g.fetchErr = fmt.Errorf("failed to fetch branch: %w", context.Canceled)
+	m := NewManagerWithGit(g, t.TempDir())
+
+	if _, err := m.Create("ABC-746", CreateOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	assertCalls(t, g, "fetch origin main")
+}
+
+// TestCreateInterrupted tests that an interrupted create removes the
+// directories it made, but not a repository directory that was there
+func TestCreateInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	m.SetContext(ctx)
+	repoDir := filepath.Join(m.basePath, "test-repo")
+	path := filepath.Join(repoDir, "ABC-746")
+
+	// Interrupted while fetching, before git touched the worktree
+	g.fetchErr = fmt.Errorf("failed to fetch branch: %w", context.Canceled)
+	if _, err := m.Create("ABC-746", CreateOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(repoDir); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected %s to be removed, got %v", repoDir, err)
+	}
+
+	// Interrupted while git was adding the worktree
+	g.fetchErr, g.createErr, g.calls = nil, context.Canceled, nil
+	if _, err := m.Create("ABC-746", CreateOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	assertCalls(t, g, "fetch origin main", "create "+path+" ABC-746 main", "remove "+path+" true", "delete-branch ABC-746")
+	if _, err := os.Stat(repoDir); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected %s to be removed, got %v", repoDir, err)
+	}
+
+	// The repository's directory is kept when it was there already
+	if err := os.MkdirAll(filepath.Join(repoDir, "ABC-1"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if _, err := m.Create("ABC-746", CreateOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected %s to be removed, got %v", path, err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "ABC-1")); err != nil {
+		t.Errorf("Expected the other worktree to be kept: %v", err)
+	}
+}
+
+// TestCreateExistingBranch tests that an existing branch is checked out
+// without fetching
+func TestCreateExistingBranch(t *testing.T) {
+	g := newMockGit()
+	g.branches["ABC-746"] = true
+	m := NewManagerWithGit(g, t.TempDir())
+
+	if _, err := m.Create("ABC-746", CreateOptions{Existing: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertCalls(t, g, "add "+filepath.Join(m.basePath, "test-repo", "ABC-746")+" ABC-746")
+}
+
+// TestCreateExistingMissingBranch tests that --existing requires the branch
+func TestCreateExistingMissingBranch(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	_, err := m.Create("ABC-746", CreateOptions{Existing: true})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected missing branch error, got %v", err)
+	}
+	assertCalls(t, g)
+}
+
+// TestCreateOptions tests that branch, remote, and track options reach git
+func TestCreateOptions(t *testing.T) {
+	g := newMockGit()
+	g.branches["upstream/develop"] = true
+	m := NewManagerWithGit(g, t.TempDir())
+
+	opts := CreateOptions{BaseBranch: "develop", Branch: "feature/login", Remote: "upstream", Track: true}
+	if _, err := m.Create("ABC-746", opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	assertCalls(t, g,
+		"fetch upstream develop",
+		"create "+path+" feature/login upstream/develop",
+		"upstream "+path+" upstream/develop")
+}
+
+// TestCreateDepth tests that a depth reaches the fetch and a negative one
+// is rejected
+func TestCreateDepth(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	if _, err := m.Create("ABC-746", CreateOptions{Depth: 1}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	assertCalls(t, g,
+		"fetch origin main --depth 1",
+		"create "+path+" ABC-746 main")
+
+	g.calls = nil
+	if _, err := m.Create("ABC-747", CreateOptions{Depth: -1}); err == nil || !strings.Contains(err.Error(), "invalid --depth -1") {
+		t.Errorf("Expected an error naming --depth for a negative depth, got %v", err)
+	}
+	assertCalls(t, g)
+}
+
+// TestCreateFetchRetries tests that the retry count reaches the fetch and a
+// negative one is rejected
+func TestCreateFetchRetries(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	if _, err := m.Create("ABC-746", CreateOptions{FetchRetries: 2}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	assertCalls(t, g,
+		"fetch origin main --retries 2",
+		"create "+path+" ABC-746 main")
+
+	g.calls = nil
+	if _, err := m.Create("ABC-747", CreateOptions{FetchRetries: -1}); err == nil {
+		t.Errorf("Expected error for negative fetch retries")
+	}
+	assertCalls(t, g)
+}
+
+// TestCreateNoFetch tests that NoFetch skips the fetch and its progress
+// message, and cannot be combined with a depth
+func TestCreateNoFetch(t *testing.T) {
+	g := newMockGit()
+	g.fetchErr = errors.New("could not resolve host")
+	m := NewManagerWithGit(g, t.TempDir())
+
+	var buf bytes.Buffer
+	m.SetOutput(&buf)
+	if _, err := m.Create("ABC-746", CreateOptions{NoFetch: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	assertCalls(t, g, "create "+path+" ABC-746 main")
+	if strings.Contains(buf.String(), "Fetching") || strings.Contains(buf.String(), "Warning") {
+		t.Errorf("Expected no fetch output, got %q", buf.String())
+	}
+
+	g.calls = nil
+	if _, err := m.Create("ABC-747", CreateOptions{NoFetch: true, Depth: 1}); err == nil {
+		t.Errorf("Expected error for --depth with --no-fetch")
+	}
+	assertCalls(t, g)
+}
+
+// TestCreateBranchCheckedOut tests that reusing a branch checked out in
+// another worktree names that worktree instead of running git
+func TestCreateBranchCheckedOut(t *testing.T) {
+	g := newMockGit()
+	g.branches["ABC-1"] = true
+	g.worktrees = append(g.worktrees,
+		GitWorktree{Path: "/src/repo", Branch: "main"},
+		GitWorktree{Path: "/tmp/experiment", Branch: "ABC-1"})
+	m := NewManagerWithGit(g, t.TempDir())
+
+	testCases := []struct {
+		branch   string
+		expected string
+	}{
+		{"main", "branch main is already checked out in the main working tree at /src/repo"},
+		{"ABC-1", "branch ABC-1 is already checked out in /tmp/experiment; switch that worktree"},
+	}
+
+	for _, tc := range testCases {
+		_, err := m.Create("ABC-746", CreateOptions{Branch: tc.branch, Existing: true})
+		if !errors.Is(err, ErrBranchCheckedOut) {
+			t.Fatalf("Expected ErrBranchCheckedOut, got %v", err)
+		}
+		if !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("Expected %q in the error, got %q", tc.expected, err)
+		}
+	}
+	assertCalls(t, g)
+}
+
+// TestCreateTrackRemote tests that a branch is only created from and tracks
+// the remote branch of the same name when asked for
+func TestCreateTrackRemote(t *testing.T) {
+	g := newMockGit()
+	g.branches["origin/ABC-1"] = true
+	m := NewManagerWithGit(g, t.TempDir())
+	path := func(ticket string) string { return filepath.Join(m.basePath, "test-repo", ticket) }
+
+	// Without asking, a remote branch of the same name is left alone
+	result, err := m.Create("ABC-1", CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Base != "main" {
+		t.Errorf("Expected base main, got %q", result.Base)
+	}
+	assertCalls(t, g, "fetch origin main", "create "+path("ABC-1")+" ABC-1 main")
+
+	// Asking for it fetches the branch first and tracks it
+	g.calls = nil
+	g.branches["origin/ABC-2"] = true
+	if _, err := m.Create("ABC-2", CreateOptions{TrackRemote: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertCalls(t, g, "fetch origin ABC-2", "track "+path("ABC-2")+" ABC-2 origin/ABC-2")
+
+	// An explicit base means a new branch from it
+	g.calls = nil
+	g.branches["origin/ABC-3"] = true
+	if _, err := m.Create("ABC-3", CreateOptions{BaseBranch: "main"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertCalls(t, g, "fetch origin main", "create "+path("ABC-3")+" ABC-3 main")
+
+	testCases := []struct {
+		name     string
+		ticket   string
+		opts     CreateOptions
+		expected error
+	}{
+		{"missing", "ABC-4", CreateOptions{TrackRemote: true}, ErrBranchNotFound},
+		{"local exists", "ABC-5", CreateOptions{TrackRemote: true}, nil},
+		{"with base", "ABC-5", CreateOptions{TrackRemote: true, BaseBranch: "main"}, nil},
+	}
+	g.branches["ABC-5"] = true
+	for _, tc := range testCases {
+		_, err := m.Create(tc.ticket, tc.opts)
+		if err == nil || tc.expected != nil && !errors.Is(err, tc.expected) {
+			t.Errorf("%s: expected an error matching %v, got %v", tc.name, tc.expected, err)
+		}
+	}
+}
+
+// TestCreateName tests that --name names the directory, which the other
+// commands then take, while the ticket still names the branch
+func TestCreateName(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	m.config.BranchPrefix = "feature/"
+	path := filepath.Join(m.basePath, "test-repo", "hotfix")
+
+	result, err := m.Create("ABC-746", CreateOptions{Name: "hotfix"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Ticket != "hotfix" || result.Path != path || result.Branch != "feature/ABC-746" {
+		t.Errorf("Expected hotfix at %s on feature/ABC-746, got %+v", path, result)
+	}
+	assertCalls(t, g, "fetch origin main", "create "+path+" feature/ABC-746 main")
+
+	if got, err := m.ExistingPath("hotfix"); err != nil || got != path {
+		t.Errorf("Expected path %q, got %q (%v)", path, got, err)
+	}
+	entries, err := m.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Ticket != "hotfix" || entries[0].Branch != "feature/ABC-746" {
+		t.Errorf("Expected hotfix on feature/ABC-746 to be listed, got %+v", entries)
+	}
+	if err := m.Delete("hotfix", DeleteOptions{DeleteBranch: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if g.branches["feature/ABC-746"] {
+		t.Errorf("Expected branch feature/ABC-746 to be deleted")
+	}
+
+	testCases := []struct {
+		name string
+		opts CreateOptions
+	}{
+		{"invalid name", CreateOptions{Name: "../x"}},
+		{"branch only", CreateOptions{Name: "hotfix", BranchOnly: true}},
+	}
+	for _, tc := range testCases {
+		if _, err := m.Create("ABC-747", tc.opts); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+	if _, err := m.CreateAll([]string{"ABC-1", "ABC-2"}, CreateOptions{Name: "hotfix"}); err == nil {
+		t.Errorf("Expected an error for --name with several tickets")
+	}
+}
+
+// TestCreateDefaultBranch tests that without a base branch the configured
+// default wins, then the remote's default branch, then main
+func TestCreateDefaultBranch(t *testing.T) {
+	testCases := []struct {
+		configured    string
+		remoteDefault string
+		expected      string
+	}{
+		{"develop", "trunk", "develop"},
+		{"", "trunk", "trunk"},
+		{"", "", "main"},
+	}
+
+	for _, tc := range testCases {
+		g := newMockGit()
+		g.defaultBranch = tc.remoteDefault
+		g.branches[tc.expected] = true
+		m := NewManagerWithGit(g, t.TempDir())
+		m.config.DefaultBaseBranch = tc.configured
+
+		if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+		assertCalls(t, g, "fetch origin "+tc.expected, "create "+path+" ABC-746 "+tc.expected)
+	}
+}
+
+// TestCreateMissingBaseBranch tests that an unknown base branch is
+// reported before any worktree is created
+func TestCreateMissingBaseBranch(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	_, err := m.Create("ABC-746", CreateOptions{BaseBranch: "nonexistent-branch"})
+	expected := "base branch 'nonexistent-branch' not found locally or on remote"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+	assertCalls(t, g, "fetch origin nonexistent-branch")
+}
+
+// TestCreateFromCurrent tests that --from-current skips the fetch
+func TestCreateFromCurrent(t *testing.T) {
+	g := newMockGit()
+	g.currentBranch = "develop"
+	g.branches["develop"] = true
+	m := NewManagerWithGit(g, t.TempDir())
+
+	if _, err := m.Create("ABC-746", CreateOptions{FromCurrent: true, Track: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	assertCalls(t, g,
+		"create "+path+" ABC-746 develop",
+		"upstream "+path+" origin/develop")
+}
+
+// TestCreateFromCurrentWithBase tests that --from-current rejects an
+// explicit base branch
+func TestCreateFromCurrentWithBase(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	_, err := m.Create("ABC-746", CreateOptions{FromCurrent: true, BaseBranch: "develop"})
+	if err == nil || !strings.Contains(err.Error(), "--from-current") {
+		t.Errorf("Expected conflicting options error, got %v", err)
+	}
+	assertCalls(t, g)
+}
+
+// TestCreateBaseFromTracking tests basing new branches on the upstream of
+// the current branch, whether it is remote or local
+func TestCreateBaseFromTracking(t *testing.T) {
+	g := newMockGit()
+	g.currentBranch = "hotfix"
+	g.remoteURL = "git@github.com:acme/app.git"
+	g.upstreams = map[string]string{"hotfix": "upstream/release-2"}
+	g.branches["upstream/release-2"] = true
+	m := NewManagerWithGit(g, t.TempDir())
+
+	result, err := m.Create("ABC-1", CreateOptions{BaseFromTracking: true, Track: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Base != "upstream/release-2" {
+		t.Errorf("Expected %q, got %q", "upstream/release-2", result.Base)
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC-1")
+	assertCalls(t, g,
+		"fetch upstream release-2",
+		"create "+path+" ABC-1 upstream/release-2",
+		"upstream "+path+" upstream/release-2")
+
+	// A local upstream is used as it is, without fetching
+	g.calls = nil
+	g.remoteURL = ""
+	g.upstreams["hotfix"] = "feature/base"
+	g.branches["feature/base"] = true
+	if _, err := m.Create("ABC-2", CreateOptions{BaseFromTracking: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertCalls(t, g, "create "+filepath.Join(m.basePath, "test-repo", "ABC-2")+" ABC-2 feature/base")
+
+	if _, err := m.Create("ABC-3", CreateOptions{BaseFromTracking: true, BaseBranch: "develop"}); err == nil ||
+		!strings.Contains(err.Error(), "--base-from-tracking") {
+		t.Errorf("Expected conflicting options error, got %v", err)
+	}
+}
+
+// TestCreateBaseRef tests that a revision base is verified and used
+// without fetching, and that a remote-tracking base is fetched from its
+// own remote
+func TestCreateBaseRef(t *testing.T) {
+	g := newMockGit()
+	g.branches["HEAD~3"] = true
+	m := NewManagerWithGit(g, t.TempDir())
+
+	result, err := m.Create("ABC-1", CreateOptions{BaseBranch: "HEAD~3"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Base != "HEAD~3" {
+		t.Errorf("Expected %q, got %q", "HEAD~3", result.Base)
+	}
+	assertCalls(t, g, "create "+filepath.Join(m.basePath, "test-repo", "ABC-1")+" ABC-1 HEAD~3")
+
+	g.calls = nil
+	if _, err := m.Create("ABC-2", CreateOptions{BaseBranch: "main~40"}); !errors.Is(err, ErrBranchNotFound) {
+		t.Errorf("Expected ErrBranchNotFound, got %v", err)
+	}
+	if _, err := m.Create("ABC-2", CreateOptions{BaseBranch: "HEAD~3", Track: true}); err == nil {
+		t.Errorf("Expected an error for --track with a revision")
+	}
+	assertCalls(t, g)
+
+	g.remoteURL = "git@github.com:acme/app.git"
+	g.branches["upstream/release-2"] = true
+	if _, err := m.Create("ABC-3", CreateOptions{BaseBranch: "upstream/release-2", Track: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC-3")
+	assertCalls(t, g,
+		"fetch upstream release-2",
+		"create "+path+" ABC-3 upstream/release-2",
+		"upstream "+path+" upstream/release-2")
+}
+
+// TestIsRevision tests telling revision expressions from branch names
+func TestIsRevision(t *testing.T) {
+	testCases := []struct {
+		ref      string
+		expected bool
+	}{
+		{"main", false},
+		{"origin/main", false},
+		{"feature/ABC-1", false},
+		{"user@example", false},
+		{"v1.2.0", false},
+		{"Head", false},
+		{"HEAD", true},
+		{"FETCH_HEAD", true},
+		{"@", true},
+		{"HEAD~3", true},
+		{"main^2", true},
+		{"main@{1}", true},
+		{":/fix typo", true},
+	}
+
+	for _, tc := range testCases {
+		if got := isRevision(tc.ref); got != tc.expected {
+			t.Errorf("isRevision(%q): expected %t, got %t", tc.ref, tc.expected, got)
+		}
+	}
+}
+
+// TestCreateBaseFromTrackingNoUpstream tests falling back to the usual
+// base branch with a warning when the current branch has no upstream
+func TestCreateBaseFromTrackingNoUpstream(t *testing.T) {
+	g := newMockGit()
+	g.currentBranch = "hotfix"
+	m := NewManagerWithGit(g, t.TempDir())
+	var out bytes.Buffer
+	m.SetOutput(&out)
+
+	if _, err := m.Create("ABC-1", CreateOptions{BaseFromTracking: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "Warning: branch hotfix has no upstream, using origin/main as the base instead"; !strings.Contains(out.String(), want) {
+		t.Errorf("Expected output to contain %q, got %q", want, out.String())
+	}
+	assertCalls(t, g,
+		"fetch origin main",
+		"create "+filepath.Join(m.basePath, "test-repo", "ABC-1")+" ABC-1 main")
+}
+
+// TestCreateRemoteBase tests that the fetched remote-tracking branch is
+// preferred over a possibly stale local base branch
+func TestCreateRemoteBase(t *testing.T) {
+	g := newMockGit()
+	g.branches["origin/main"] = true
+	m := NewManagerWithGit(g, t.TempDir())
+
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	assertCalls(t, g,
+		"fetch origin main",
+		"create "+path+" ABC-746 origin/main")
+}
+
+// TestCreateBranchFrom tests starting a branch at a tag without fetching,
+// and rejecting unknown refs and conflicting options
+func TestCreateBranchFrom(t *testing.T) {
+	g := newMockGit()
+	g.branches["v1.2.0"] = true
+	m := NewManagerWithGit(g, t.TempDir())
+
+	if _, err := m.Create("ABC-1", CreateOptions{BranchFrom: "v1.2.0"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertCalls(t, g, "create "+filepath.Join(m.basePath, "test-repo", "ABC-1")+" ABC-1 v1.2.0")
+
+	testCases := []struct {
+		name string
+		opts CreateOptions
+	}{
+		{"missing ref", CreateOptions{BranchFrom: "v9.9.9"}},
+		{"with base", CreateOptions{BranchFrom: "v1.2.0", BaseBranch: "develop"}},
+		{"with current", CreateOptions{BranchFrom: "v1.2.0", FromCurrent: true}},
+		{"with track", CreateOptions{BranchFrom: "v1.2.0", Track: true}},
+	}
+
+	for _, tc := range testCases {
+		g.calls = nil
+		if _, err := m.Create("ABC-2", tc.opts); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+		assertCalls(t, g)
+	}
+
+	if _, err := m.Create("ABC-2", CreateOptions{BranchFrom: "v9.9.9"}); !errors.Is(err, ErrBranchNotFound) {
+		t.Errorf("Expected ErrBranchNotFound, got %v", err)
+	}
+}
+
+// TestCreateBranchOnly tests creating just the branch, leaving nothing on
+// disk until it is checked out with Existing
+func TestCreateBranchOnly(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	result, err := m.Create("ABC-746", CreateOptions{BranchOnly: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := CreateResult{Ticket: "ABC-746", Branch: "ABC-746", Base: "main"}
+	if result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+	assertCalls(t, g, "fetch origin main", "branch ABC-746 main")
+	if _, err := os.Stat(filepath.Join(m.basePath, "test-repo")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected no repository directory, got %v", err)
+	}
+
+	testCases := []struct {
+		name string
+		opts CreateOptions
+	}{
+		{"existing branch", CreateOptions{BranchOnly: true}},
+		{"with existing", CreateOptions{BranchOnly: true, Existing: true}},
+		{"with track", CreateOptions{BranchOnly: true, Track: true}},
+	}
+
+	for _, tc := range testCases {
+		g.calls = nil
+		if _, err := m.Create("ABC-746", tc.opts); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+		assertCalls(t, g)
+	}
+
+	g.calls = nil
+	if _, err := m.Create("ABC-746", CreateOptions{Existing: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertCalls(t, g, "add "+filepath.Join(m.basePath, "test-repo", "ABC-746")+" ABC-746")
+}
+
+// TestCreateBare tests that a bare repository skips copy_on_create rather
+// than failing to find a working tree
+func TestCreateBare(t *testing.T) {
+	g := newMockGit()
+	g.bare = true
+	m := NewManagerWithGit(g, t.TempDir())
+	m.config.CopyOnCreate = []string{".env"}
+
+	var buf bytes.Buffer
+	m.SetOutput(&buf)
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "skipping copy_on_create") {
+		t.Errorf("Expected copy_on_create to be skipped, got %q", buf.String())
+	}
+}
+
+// TestCreateCopyIgnored tests that copy_on_create only copies the files
+// git ignores, unless CopyAll is set
+func TestCreateCopyIgnored(t *testing.T) {
+	g := newMockGit()
+	g.toplevel = t.TempDir()
+	for _, name := range []string{".env", "config/app.local.yaml", "config/app.yaml", "Makefile", "certs/dev.pem"} {
+		writeFile(t, filepath.Join(g.toplevel, name), name)
+	}
+	// An ignored directory is copied whole, whatever git says of its files
+	g.ignored = map[string]bool{
+		filepath.Join(g.toplevel, ".env"):                     true,
+		filepath.Join(g.toplevel, "config", "app.local.yaml"): true,
+		filepath.Join(g.toplevel, "certs"):                    true,
+	}
+	m := NewManagerWithGit(g, t.TempDir())
+	m.config.CopyOnCreate = []string{".env", "config", "Makefile", "certs"}
+
+	testCases := []struct {
+		ticket  string
+		opts    CreateOptions
+		copied  []string
+		skipped []string
+	}{
+		{"ABC-746", CreateOptions{}, []string{".env", "config/app.local.yaml", "certs/dev.pem"}, []string{"config/app.yaml", "Makefile"}},
+		{"ABC-747", CreateOptions{CopyAll: true}, []string{".env", "config/app.local.yaml", "config/app.yaml", "Makefile", "certs/dev.pem"}, nil},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		m.SetOutput(&buf)
+		if _, err := m.Create(tc.ticket, tc.opts); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.ticket, err)
+		}
+		dir := filepath.Join(m.basePath, "test-repo", tc.ticket)
+		for _, name := range tc.copied {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Errorf("%s: expected %s to be copied: %v", tc.ticket, name, err)
+			}
+		}
+		for _, name := range tc.skipped {
+			if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+				t.Errorf("%s: expected %s not to be copied", tc.ticket, name)
+			}
+			if !strings.Contains(buf.String(), "Skipping "+filepath.FromSlash(name)) {
+				t.Errorf("%s: expected %s to be reported as skipped, got %q", tc.ticket, name, buf.String())
+			}
+		}
+	}
+}
+
+// TestCreateTemplate tests seeding a new worktree from template_dir, and
+// going on without it when the directory is missing
+func TestCreateTemplate(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	m.config.TemplateDir = filepath.Join(t.TempDir(), "scaffold")
+	writeFile(t, filepath.Join(m.config.TemplateDir, "notes", "TODO.md"), "- [ ] write tests")
+
+	var buf bytes.Buffer
+	m.SetOutput(&buf)
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746", "notes", "TODO.md")
+	if data, err := os.ReadFile(path); err != nil || string(data) != "- [ ] write tests" {
+		t.Errorf("Expected the template to be copied to %s, got %q, %v", path, data, err)
+	}
+	if !strings.Contains(buf.String(), "Copied 1 file from template") {
+		t.Errorf("Expected the copy to be reported, got %q", buf.String())
+	}
+
+	m.config.TemplateDir = filepath.Join(t.TempDir(), "missing")
+	buf.Reset()
+	if _, err := m.Create("ABC-747", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "is not a directory, skipping it") {
+		t.Errorf("Expected a missing template_dir to be skipped, got %q", buf.String())
+	}
+}
+
+// TestCreateSanitizedBranch tests that a ticket with characters git
+// doesn't allow keeps its directory name but gets a valid branch, with a
+// warning
+func TestCreateSanitizedBranch(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	var buf bytes.Buffer
+	m.SetOutput(&buf)
+	result, err := m.Create("ABC 746: login", CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC 746: login")
+	if result.Path != path || result.Branch != "ABC-746-login" {
+		t.Errorf("Expected branch ABC-746-login at %s, got %+v", path, result)
+	}
+	if !strings.Contains(buf.String(), "using branch ABC-746-login") {
+		t.Errorf("Expected a warning about the branch name, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := m.Create("ABC-747", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "Warning") {
+		t.Errorf("Expected no warning for a valid ticket, got %q", buf.String())
+	}
+}
+
+// TestCreateDirectoryExists tests that an existing directory is not reused
+func TestCreateDirectoryExists(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if err := os.MkdirAll(filepath.Join(m.basePath, "test-repo", "ABC-746"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	_, err := m.Create("ABC-746", CreateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected directory exists error, got %v", err)
+	}
+	assertCalls(t, g)
+}
+
+// TestDelete tests removing a worktree together with its branch
+func TestDelete(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{Branch: "feature/login"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	g.calls = nil
+
+	if err := m.Delete("ABC-746", DeleteOptions{DeleteBranch: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	assertCalls(t, g,
+		"remove "+path+" false",
+		"delete-branch feature/login")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed", path)
+	}
+}
+
+// TestDeleteKeepDir tests unregistering a worktree while moving its files
+// out of the managed directory
+func TestDeleteKeepDir(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	writeFile(t, filepath.Join(path, ".git"), "gitdir: /src/repo/.git/worktrees/ABC-746\n")
+	writeFile(t, filepath.Join(path, "notes.txt"), "draft")
+	g.calls = nil
+
+	if err := m.Delete("ABC-746", DeleteOptions{KeepDir: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertCalls(t, g, "remove "+path+" true")
+
+	kept := filepath.Join(m.basePath, KeptDir, "test-repo", "ABC-746")
+	if data, err := os.ReadFile(filepath.Join(kept, "notes.txt")); err != nil || string(data) != "draft" {
+		t.Errorf("Expected notes.txt to be kept in %s, got %q, %v", kept, data, err)
+	}
+	if _, err := os.Stat(filepath.Join(kept, ".git")); !os.IsNotExist(err) {
+		t.Errorf("Expected the .git file to be removed from %s", kept)
+	}
+	entries, err := m.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected the kept worktree not to be listed, got %+v", entries)
+	}
+
+	// Keeping the same ticket again would overwrite the first copy
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := m.Delete("ABC-746", DeleteOptions{KeepDir: true}); !errors.Is(err, ErrWorktreeExists) {
+		t.Errorf("Expected ErrWorktreeExists, got %v", err)
+	}
+}
+
+// TestDeleteKeepDirLocked tests that a worktree git refuses to unregister
+// is moved back into place
+func TestDeleteKeepDirLocked(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := m.Lock("ABC-746", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err := m.Delete("ABC-746", DeleteOptions{KeepDir: true})
+	if err == nil || !strings.Contains(err.Error(), "unlock it first") {
+		t.Errorf("Expected locked worktree error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.basePath, "test-repo", "ABC-746")); err != nil {
+		t.Errorf("Expected the worktree to be moved back: %v", err)
+	}
+}
+
+// TestDeleteDryRun tests that a dry run leaves the worktree in place
+func TestDeleteDryRun(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	g.calls = nil
+
+	var buf bytes.Buffer
+	m.SetOutput(&buf)
+	m.SetDryRun(&buf)
+	if err := m.Delete("ABC-746", DeleteOptions{DeleteBranch: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	assertCalls(t, g,
+		"remove "+path+" false",
+		"delete-branch ABC-746")
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected %s to be kept: %v", path, err)
+	}
+	if !strings.Contains(buf.String(), "Dry run, nothing was changed") {
+		t.Errorf("Expected dry-run summary, got %q", buf.String())
+	}
+}
+
+// TestDeleteProtectedBranch tests that a protected branch survives -d
+// unless ForceProtected is set, while the worktree is still removed
+func TestDeleteProtectedBranch(t *testing.T) {
+	for _, force := range []bool{false, true} {
+		g := newMockGit()
+		g.branches["develop"] = true
+		m := NewManagerWithGit(g, t.TempDir())
+		if _, err := m.Create("ABC-746", CreateOptions{Branch: "develop", Existing: true}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		g.calls = nil
+
+		var buf bytes.Buffer
+		m.SetOutput(&buf)
+		if err := m.Delete("ABC-746", DeleteOptions{DeleteBranch: true, ForceProtected: force}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+		if force {
+			assertCalls(t, g, "remove "+path+" false", "delete-branch develop")
+			continue
+		}
+		assertCalls(t, g, "remove "+path+" false")
+		if !strings.Contains(buf.String(), "refusing to delete protected branch") {
+			t.Errorf("Expected refusal message, got %q", buf.String())
+		}
+	}
+}
+
+// TestDeleteNotFound tests deleting a ticket with no worktree
+func TestDeleteNotFound(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	err := m.Delete("ABC-746", DeleteOptions{})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	assertCalls(t, g)
+}
+
+// TestDeleteDirty tests that a dirty worktree suggests -f
+func TestDeleteDirty(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	g.removeErr = errors.New("fatal: '/tmp/x' contains modified or untracked files, use --force to delete it")
+
+	err := m.Delete("ABC-746", DeleteOptions{DeleteBranch: true})
+	if err == nil || !strings.Contains(err.Error(), "use -f") {
+		t.Errorf("Expected dirty worktree error, got %v", err)
+	}
+	if !g.branches["ABC-746"] {
+		t.Errorf("Expected branch to be kept when removal fails")
+	}
+}
+
+// TestDeleteAll tests that every worktree is attempted and failures are
+// reported per ticket
+func TestDeleteAll(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	for _, ticket := range []string{"ABC-1", "ABC-2"} {
+		if _, err := m.Create(ticket, CreateOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	// An unregistered directory fails to remove but doesn't stop the rest
+	if err := os.MkdirAll(filepath.Join(m.basePath, "test-repo", "ABC-0"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	g.calls = nil
+
+	results, err := m.DeleteAll(true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %+v", results)
+	}
+	if results[0].Ticket != "ABC-0" || results[0].Err == nil {
+		t.Errorf("Expected ABC-0 to fail, got %+v", results[0])
+	}
+	for _, result := range results[1:] {
+		if result.Err != nil {
+			t.Errorf("Expected %s to be removed, got %v", result.Ticket, result.Err)
+		}
+	}
+	if g.branches["ABC-1"] || g.branches["ABC-2"] {
+		t.Errorf("Expected branches to be deleted, got %v", g.branches)
+	}
+}
+
+// TestDeleteTickets tests removing several worktrees named by full,
+// partial, and repeated tickets, carrying on past failures and keeping
+// protected branches
+func TestDeleteTickets(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	for ticket, branch := range map[string]string{"ABC-1": "", "ABC-2": "", "XYZ-3": "develop"} {
+		if _, err := m.Create(ticket, CreateOptions{Branch: branch}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	var out bytes.Buffer
+	m.SetOutput(&out)
+
+	results := m.DeleteTickets([]string{"ABC-1", "2", "XYZ-3", "NOPE-9", "abc-1"}, DeleteOptions{DeleteBranch: true})
+	expected := []struct {
+		ticket string
+		err    error
+	}{
+		{"ABC-1", nil},
+		{"ABC-2", nil},
+		{"XYZ-3", nil},
+		{"NOPE-9", ErrWorktreeNotFound},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %+v", len(expected), results)
+	}
+	for i, want := range expected {
+		result := results[i]
+		if result.Ticket != want.ticket || !errors.Is(result.Err, want.err) || (want.err == nil) != (result.Path != "") {
+			t.Errorf("Expected %s with error %v, got %+v", want.ticket, want.err, result)
+		}
+	}
+
+	if g.branches["ABC-1"] || g.branches["ABC-2"] {
+		t.Errorf("Expected branches to be deleted, got %v", g.branches)
+	}
+	if !g.branches["develop"] {
+		t.Errorf("Expected the protected branch develop to be kept")
+	}
+	for _, want := range []string{"[ABC-1] ", "[ABC-2] ", "[XYZ-3] Warning: refusing to delete protected branch develop"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in the output, got %q", want, out.String())
+		}
+	}
+}
+
+// TestErrorKinds tests that failures can be told apart with errors.Is
+func TestErrorKinds(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	for _, ticket := range []string{"ABC-1", "ABC-2"} {
+		if _, err := m.Create(ticket, CreateOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	create := func(ticket string, opts CreateOptions) error {
+		_, err := m.Create(ticket, opts)
+		return err
+	}
+
+	testCases := []struct {
+		name     string
+		run      func() error
+		expected error
+	}{
+		{"exists", func() error { return create("ABC-1", CreateOptions{}) }, ErrWorktreeExists},
+		{"not found", func() error { return m.Delete("XYZ-9", DeleteOptions{}) }, ErrWorktreeNotFound},
+		{"no path", func() error {
+			_, err := m.ExistingPath("XYZ-9")
+			return err
+		}, ErrWorktreeNotFound},
+		{"not a directory", func() error {
+			writeFile(t, filepath.Join(m.basePath, "test-repo", "XYZ-8"), "")
+			_, err := m.ExistingPath("XYZ-8")
+			return err
+		}, ErrWorktreeNotFound},
+		{"missing branch", func() error { return create("ABC-3", CreateOptions{Existing: true}) }, ErrBranchNotFound},
+		{"checked out", func() error { return create("ABC-3", CreateOptions{Branch: "ABC-2"}) }, ErrBranchCheckedOut},
+		{"missing base", func() error { return create("ABC-3", CreateOptions{BaseBranch: "nope"}) }, ErrBranchNotFound},
+		{"invalid", func() error { return create("../x", CreateOptions{}) }, ErrInvalidTicket},
+		{"ambiguous", func() error { return m.Delete("ABC", DeleteOptions{}) }, ErrAmbiguousTicket},
+		{"dirty", func() error {
+			g.removeErr = errors.New("fatal: '/x' contains modified or untracked files, use --force to delete it")
+			defer func() { g.removeErr = nil }()
+			return m.Delete("ABC-1", DeleteOptions{})
+		}, ErrWorktreeDirty},
+		{"not a repo", func() error {
+			g.repoErr = fmt.Errorf("%w: exit status 128", ErrNotARepo)
+			defer func() { g.repoErr = nil }()
+			m.ResetCache()
+			_, err := m.GetPath("ABC-1")
+			return err
+		}, ErrNotARepo},
+	}
+
+	for _, tc := range testCases {
+		if err := tc.run(); !errors.Is(err, tc.expected) {
+			t.Errorf("%s: expected errors.Is(%v, %v)", tc.name, err, tc.expected)
+		}
+	}
+}
+
+// TestRepoNameCached tests that git is asked for the repository name once
+// per working directory and again after ResetCache
+func TestRepoNameCached(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := m.List(ListOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := m.GetPath("ABC-746"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if g.repoNameCalls != 1 {
+		t.Errorf("Expected 1 repo name lookup, got %d", g.repoNameCalls)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	if _, err := m.RepoName(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if g.repoNameCalls != 2 {
+		t.Errorf("Expected a new lookup after changing directory, got %d lookups", g.repoNameCalls)
+	}
+
+	m.ResetCache()
+	if _, err := m.RepoName(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if g.repoNameCalls != 3 {
+		t.Errorf("Expected a new lookup after ResetCache, got %d lookups", g.repoNameCalls)
+	}
+}
+
+// TestRepoNamespace tests the repository namespace with repo_key and
+// namespace_from, for a fork whose origin and upstream differ
+func TestRepoNamespace(t *testing.T) {
+	testCases := []struct {
+		name          string
+		repoKey       string
+		namespaceFrom string
+		remoteURLs    map[string]string
+		expected      string
+	}{
+		{"basename", "", "", nil, "test-repo"},
+		{"origin", config.RepoKeyRemote, "", map[string]string{"origin": "git@github.com:me/app.git"}, "me/app"},
+		{
+			"upstream",
+			config.RepoKeyRemote, config.NamespaceFromUpstream,
+			map[string]string{"origin": "git@github.com:me/app.git", "upstream": "https://github.com/acme/app.git"},
+			"acme/app",
+		},
+		{
+			"upstream implies remote",
+			"", config.NamespaceFromUpstream,
+			map[string]string{"origin": "git@github.com:me/app.git", "upstream": "ssh://git@github.com/acme/app.git"},
+			"acme/app",
+		},
+		{"no upstream", "", config.NamespaceFromUpstream, map[string]string{"origin": "git@github.com:me/app.git"}, "me/app"},
+		{"no remotes", "", config.NamespaceFromUpstream, nil, "test-repo"},
+	}
+
+	for _, tc := range testCases {
+		g := newMockGit()
+		g.remoteURLs = tc.remoteURLs
+		m := NewManagerWithGit(g, t.TempDir())
+		m.config.RepoKey, m.config.NamespaceFrom = tc.repoKey, tc.namespaceFrom
+
+		repo, err := m.RepoName()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if repo != filepath.FromSlash(tc.expected) {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, repo)
+		}
+	}
+
+	m := NewManagerWithGit(newMockGit(), t.TempDir())
+	m.config.RepoKey, m.config.NamespaceFrom = config.RepoKeyBasename, config.NamespaceFromUpstream
+	if _, err := m.RepoName(); err == nil {
+		t.Errorf("Expected an error for namespace_from upstream with repo_key basename")
+	}
+}
+
+// TestRepoNameNormalized tests that worktrees are placed under the
+// normalized repository name, unless one under the name git reports
+// already exists
+func TestRepoNameNormalized(t *testing.T) {
+	g := newMockGit()
+	g.repoName = "My App.git"
+	m := NewManagerWithGit(g, t.TempDir())
+
+	result, err := m.Create("ABC-746", CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := filepath.Join(m.basePath, "My-App", "ABC-746"); result.Path != expected {
+		t.Errorf("Expected %q, got %q", expected, result.Path)
+	}
+
+	m = NewManagerWithGit(g, t.TempDir())
+	m.config.LowercaseRepoName = true
+	if repo, err := m.RepoName(); err != nil || repo != "my-app" {
+		t.Errorf("Expected %q, got %q, %v", "my-app", repo, err)
+	}
+
+	m = NewManagerWithGit(g, t.TempDir())
+	if err := os.MkdirAll(filepath.Join(m.basePath, "My App.git", "ABC-1"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if repo, err := m.RepoName(); err != nil || repo != "My App.git" {
+		t.Errorf("Expected the existing directory %q to be kept, got %q, %v", "My App.git", repo, err)
+	}
+}
+
+// TestBasePathHoldsRepo tests that a base path putting the worktree
+// directory at or above the main checkout is refused
+func TestBasePathHoldsRepo(t *testing.T) {
+	root := t.TempDir()
+	testCases := []struct {
+		name     string
+		checkout string
+		basePath string
+		valid    bool
+	}{
+		{"worktree directory is the checkout", filepath.Join(root, "test-repo"), root, false},
+		{"worktree directory above the checkout", filepath.Join(root, "test-repo", "src", "app"), root, false},
+		{"separate base path", filepath.Join(root, "src", "test-repo"), filepath.Join(root, "worktrees"), true},
+	}
+
+	for _, tc := range testCases {
+		if err := os.MkdirAll(filepath.Join(tc.checkout, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		g := newMockGit()
+		g.worktrees = []GitWorktree{{Path: tc.checkout, Branch: "main"}}
+		m := NewManagerWithGit(g, tc.basePath)
+
+		_, err := m.RepoName()
+		if tc.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.valid && (err == nil || !strings.Contains(err.Error(), "which holds the repository itself")) {
+			t.Errorf("%s: expected the base path to be refused, got %v", tc.name, err)
+		}
+	}
+}
+
+// TestCurrentTicket tests resolving "@" from inside a worktree and the
+// error outside one
+func TestCurrentTicket(t *testing.T) {
+	g := newMockGit()
+	m := NewManagerWithGit(g, t.TempDir())
+	if _, err := m.Create("ABC-746", CreateOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(m.basePath, "test-repo", "ABC-746")
+	subdir := filepath.Join(path, "src")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	if err := os.Chdir(subdir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	got, err := m.GetPath(CurrentTicketToken)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != path {
+		t.Errorf("Expected %s, got %s", path, got)
+	}
+
+	if err := os.Chdir(m.basePath); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	if _, err := m.GetPath(CurrentTicketToken); !errors.Is(err, ErrNotInWorktree) {
+		t.Errorf("Expected ErrNotInWorktree, got %v", err)
+	}
+}